@@ -0,0 +1,56 @@
+package escpos
+
+type AztecConfig struct {
+	mode            string
+	layers          uint8
+	moduleSize      uint8
+	errorCorrection uint8
+	justification   string
+}
+
+// DefaultAztecConfig creates an AztecConfig containing sensible default
+// values for Aztec printing.
+func DefaultAztecConfig() AztecConfig {
+	return AztecConfig{
+		mode:            "full",
+		layers:          0,
+		moduleSize:      3,
+		errorCorrection: 23,
+		justification:   "center",
+	}
+}
+
+// Mode sets the symbol type, which is usually full or compact. The
+// default is full.
+func (cfg AztecConfig) Mode(mode string) AztecConfig {
+	cfg.mode = mode
+	return cfg
+}
+
+// Layers sets the number of data layers, from 1 to 32 for full range
+// symbols and 1 to 4 for compact symbols. The default of 0 lets the
+// printer choose.
+func (cfg AztecConfig) Layers(layers uint8) AztecConfig {
+	cfg.layers = layers
+	return cfg
+}
+
+// ModuleSize sets the width and height in dots of a module. Most
+// printers support 2 to 16. The default is 3.
+func (cfg AztecConfig) ModuleSize(size uint8) AztecConfig {
+	cfg.moduleSize = size
+	return cfg
+}
+
+// ErrorCorrection sets the percentage of the symbol used for error
+// correction, from 5 to 95. The default is 23.
+func (cfg AztecConfig) ErrorCorrection(percentage uint8) AztecConfig {
+	cfg.errorCorrection = percentage
+	return cfg
+}
+
+// Justify sets the Aztec symbol justification. The default is center.
+func (cfg AztecConfig) Justify(justification string) AztecConfig {
+	cfg.justification = justification
+	return cfg
+}
@@ -0,0 +1,248 @@
+package escpos
+
+import "strings"
+
+type BarcodeConfig struct {
+	symbology     string
+	width         uint8
+	height        uint8
+	hriPosition   string
+	hriFont       string
+	justification string
+}
+
+// DefaultBarcodeConfig creates a BarcodeConfig containing sensible
+// default values for barcode printing.
+func DefaultBarcodeConfig() BarcodeConfig {
+	return BarcodeConfig{
+		symbology:     "CODE128",
+		width:         3,
+		height:        162,
+		hriPosition:   "below",
+		hriFont:       "A",
+		justification: "center",
+	}
+}
+
+// Symbology sets the barcode symbology. Supported values usually include
+// UPC-A, UPC-E, EAN-13, EAN-8, CODE39, ITF, CODABAR, CODE93, CODE128 and
+// GS1-128. GS1-128 data is given in bracketed Application Identifier
+// syntax, such as (01)09501101530003(10)ABC123. CODE128 and GS1-128
+// barcodes switch between code sets to keep the symbol as narrow as
+// possible. The default is CODE128.
+func (cfg BarcodeConfig) Symbology(symbology string) BarcodeConfig {
+	cfg.symbology = symbology
+	return cfg
+}
+
+// Width sets the width in dots of the narrowest bar (the module
+// width). Most printers support 2 to 6. The default is 3.
+func (cfg BarcodeConfig) Width(width uint8) BarcodeConfig {
+	cfg.width = width
+	return cfg
+}
+
+// Height sets the height of the barcode in dots. The default is 162.
+func (cfg BarcodeConfig) Height(height uint8) BarcodeConfig {
+	cfg.height = height
+	return cfg
+}
+
+// HriPosition sets where the human readable interpretation (HRI) of the
+// data is printed. Supported values usually include none, above, below
+// and both. The default is below.
+func (cfg BarcodeConfig) HriPosition(position string) BarcodeConfig {
+	cfg.hriPosition = position
+	return cfg
+}
+
+// HriFont sets the font used for the human readable interpretation.
+// Supported values usually include A and B. The default is A.
+func (cfg BarcodeConfig) HriFont(font string) BarcodeConfig {
+	cfg.hriFont = font
+	return cfg
+}
+
+// Justify sets the barcode justification. The default is center.
+func (cfg BarcodeConfig) Justify(justification string) BarcodeConfig {
+	cfg.justification = justification
+	return cfg
+}
+
+const (
+	code39Charset  = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ -.$/+%"
+	codabarCharset = "0123456789-$:/.+"
+	codabarGuards  = "ABCDabcd"
+)
+
+// validateBarcodeData checks the data can be encoded in the symbology of
+// the config, including its charset, length and any check digit.
+func validateBarcodeData(data string, cfg *BarcodeConfig) error {
+	if len(data) < 1 || len(data) > 255 {
+		return invalidConfig("invalid data length for %v barcode: %v", cfg.symbology, len(data))
+	}
+
+	switch cfg.symbology {
+	case "UPC-A":
+		return validateCheckDigit(cfg.symbology, data, 11)
+	case "EAN-13":
+		return validateCheckDigit(cfg.symbology, data, 12)
+	case "EAN-8":
+		return validateCheckDigit(cfg.symbology, data, 7)
+	case "UPC-E":
+		return validateUpcE(data)
+	case "CODE39":
+		trimmed := data
+		if len(data) >= 2 && data[0] == '*' && data[len(data)-1] == '*' {
+			trimmed = data[1 : len(data)-1]
+		}
+		if len(trimmed) < 1 || !onlyContains(trimmed, code39Charset) {
+			return invalidConfig("invalid data for CODE39 barcode: %q", data)
+		}
+	case "ITF":
+		if !isDigits(data) || len(data)%2 != 0 {
+			return invalidConfig("invalid data for ITF barcode, expected even number of digits: %q", data)
+		}
+	case "CODABAR":
+		last := len(data) - 1
+		if last < 1 || !strings.ContainsRune(codabarGuards, rune(data[0])) ||
+			!strings.ContainsRune(codabarGuards, rune(data[last])) || !onlyContains(data[1:last], codabarCharset) {
+			return invalidConfig("invalid data for CODABAR barcode, expected start and stop characters A-D: %q", data)
+		}
+	case "CODE128", "GS1-128":
+		_, err := optimiseCode128(data, cfg.symbology)
+		return err
+	case "CODE93":
+		for i := 0; i < len(data); i++ {
+			if data[i] > 127 {
+				return invalidConfig("invalid data for %v barcode, expected ASCII: %q", cfg.symbology, data)
+			}
+		}
+	default:
+		return invalidConfig("invalid symbology option in BarcodeConfig: %v", cfg.symbology)
+	}
+
+	return nil
+}
+
+// barcodeModules returns the width in modules of the barcode, if it is
+// known for the symbology of the config.
+func barcodeModules(data string, cfg *BarcodeConfig) (int, bool) {
+	switch cfg.symbology {
+	case "CODE128", "GS1-128":
+		chars, err := optimiseCode128(data, cfg.symbology)
+		if err != nil {
+			return 0, false
+		}
+		return code128Modules(chars), true
+	default:
+		return 0, false
+	}
+}
+
+// validateCheckDigit checks the data is all digits, either without a
+// check digit or with a correct one appended.
+func validateCheckDigit(symbology string, data string, length int) error {
+	if !isDigits(data) || (len(data) != length && len(data) != length+1) {
+		return invalidConfig("invalid data for %v barcode, expected %v or %v digits: %q", symbology, length, length+1, data)
+	}
+
+	if len(data) == length+1 && checkDigit(data[:length]) != data[length] {
+		return invalidConfig("invalid check digit for %v barcode: %q, expected %c", symbology, data, checkDigit(data[:length]))
+	}
+
+	return nil
+}
+
+// validateUpcE checks UPC-E data, given either as the 6 compressed
+// digits, the compressed digits with a leading number system 0 and an
+// optional check digit, or as UPC-A data which can be compressed.
+func validateUpcE(data string) error {
+	if !isDigits(data) {
+		return invalidConfig("invalid data for UPC-E barcode, expected digits: %q", data)
+	}
+
+	switch len(data) {
+	case 6:
+		return nil
+	case 7, 8:
+		if data[0] != '0' {
+			return invalidConfig("invalid number system for UPC-E barcode, expected 0: %q", data)
+		}
+		return validateCheckDigit("UPC-E", expandUpcE(data[1:7])+data[7:], 11)
+	case 11, 12:
+		if data[0] != '0' {
+			return invalidConfig("invalid number system for UPC-E barcode, expected 0: %q", data)
+		}
+		if _, ok := compressUpcA(data[:11]); !ok {
+			return invalidConfig("invalid data for UPC-E barcode, UPC-A data cannot be compressed: %q", data)
+		}
+		return validateCheckDigit("UPC-E", data, 11)
+	default:
+		return invalidConfig("invalid data for UPC-E barcode, expected 6, 7, 8, 11 or 12 digits: %q", data)
+	}
+}
+
+// expandUpcE returns the 11 digit UPC-A data, without check digit, that
+// the 6 compressed UPC-E digits represent in number system 0.
+func expandUpcE(digits string) string {
+	switch digits[5] {
+	case '0', '1', '2':
+		return "0" + digits[0:2] + digits[5:6] + "0000" + digits[2:5]
+	case '3':
+		return "0" + digits[0:3] + "00000" + digits[3:5]
+	case '4':
+		return "0" + digits[0:4] + "00000" + digits[4:5]
+	default:
+		return "0" + digits[0:5] + "0000" + digits[5:6]
+	}
+}
+
+// compressUpcA returns the 6 UPC-E digits representing the 11 digit
+// UPC-A data, if it can be compressed.
+func compressUpcA(data string) (string, bool) {
+	manufacturer, product := data[1:6], data[6:11]
+
+	var digits string
+	switch {
+	case strings.HasSuffix(manufacturer, "00") && manufacturer[2] <= '2' && strings.HasPrefix(product, "00"):
+		digits = manufacturer[0:2] + product[2:5] + manufacturer[2:3]
+	case strings.HasSuffix(manufacturer, "00") && strings.HasPrefix(product, "000"):
+		digits = manufacturer[0:3] + product[3:5] + "3"
+	case strings.HasSuffix(manufacturer, "0") && strings.HasPrefix(product, "0000"):
+		digits = manufacturer[0:4] + product[4:5] + "4"
+	case strings.HasPrefix(product, "0000") && product[4] >= '5':
+		digits = manufacturer + product[4:5]
+	default:
+		return "", false
+	}
+
+	return digits, expandUpcE(digits) == data
+}
+
+// checkDigit calculates the modulo 10 check digit used by UPC and EAN
+// barcodes, weighting digits by 3 and 1 from the right.
+func checkDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		weight := 1
+		if (len(digits)-i)%2 == 1 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	return onlyContains(s, "0123456789")
+}
+
+func onlyContains(s string, charset string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune(charset, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package escpos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateBarcodeData(t *testing.T) {
+	cases := []struct {
+		name      string
+		symbology string
+		data      string
+		valid     bool
+	}{
+		{"UPC-A without check digit is valid", "UPC-A", "03600029145", true},
+		{"UPC-A with correct check digit is valid", "UPC-A", "036000291452", true},
+		{"UPC-A with wrong check digit is invalid", "UPC-A", "036000291453", false},
+		{"UPC-A with letters is invalid", "UPC-A", "0360002914A", false},
+		{"UPC-A with wrong length is invalid", "UPC-A", "0360002914", false},
+		{"UPC-E with 6 digits is valid", "UPC-E", "123456", true},
+		{"UPC-E with correct check digit is valid", "UPC-E", "01234565", true},
+		{"UPC-E with wrong check digit is invalid", "UPC-E", "01234566", false},
+		{"UPC-E with number system 1 is invalid", "UPC-E", "1234565", false},
+		{"UPC-E as compressible UPC-A is valid", "UPC-E", "012345000065", true},
+		{"UPC-E as incompressible UPC-A is invalid", "UPC-E", "036000291452", false},
+		{"EAN-13 with correct check digit is valid", "EAN-13", "4006381333931", true},
+		{"EAN-13 with wrong check digit is invalid", "EAN-13", "4006381333932", false},
+		{"EAN-8 with correct check digit is valid", "EAN-8", "96385074", true},
+		{"EAN-8 with wrong length is invalid", "EAN-8", "963850", false},
+		{"CODE39 with supported characters is valid", "CODE39", "ABC-1 $/+%.", true},
+		{"CODE39 with start and stop characters is valid", "CODE39", "*ABC*", true},
+		{"CODE39 with lowercase is invalid", "CODE39", "abc", false},
+		{"CODE39 with inner asterisk is invalid", "CODE39", "A*B", false},
+		{"ITF with even digits is valid", "ITF", "123456", true},
+		{"ITF with odd digits is invalid", "ITF", "12345", false},
+		{"CODABAR with guards is valid", "CODABAR", "A40156B", true},
+		{"CODABAR without guards is invalid", "CODABAR", "40156", false},
+		{"CODE93 with ASCII is valid", "CODE93", "Hello, world!", true},
+		{"CODE128 with ASCII is valid", "CODE128", "Order #123", true},
+		{"CODE128 with non-ASCII is invalid", "CODE128", "café", false},
+		{"unknown symbology is invalid", "PLESSEY", "123", false},
+		{"empty data is invalid", "CODE128", "", false},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := validateBarcodeData(testCase.data, &BarcodeConfig{symbology: testCase.symbology})
+
+			if testCase.valid && err != nil {
+				t.Errorf("validateBarcodeData returned error for valid data: %v", err)
+			}
+
+			if !testCase.valid && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("validateBarcodeData did not return config error for invalid data, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package escpos
+
+import "strings"
+
+// barcodeBars accumulates the dots across a rendered barcode, with
+// printed (bar) dots true.
+type barcodeBars []bool
+
+// modules appends the pattern of modules, where '1' is a bar and '0' a
+// space, each the given number of dots wide.
+func (bars *barcodeBars) modules(pattern string, width int) {
+	for _, module := range pattern {
+		for i := 0; i < width; i++ {
+			*bars = append(*bars, module == '1')
+		}
+	}
+}
+
+// elements appends the alternating bars and spaces, starting with a
+// bar, of a pattern of narrow ('n') and wide ('w') elements.
+func (bars *barcodeBars) elements(pattern string, narrow int, wide int) {
+	for i, element := range pattern {
+		width := narrow
+		if element == 'w' {
+			width = wide
+		}
+		for j := 0; j < width; j++ {
+			*bars = append(*bars, i%2 == 0)
+		}
+	}
+}
+
+// widths appends the alternating bars and spaces, starting with a bar,
+// of a pattern of element widths in modules.
+func (bars *barcodeBars) widths(pattern string, width int) {
+	for i, element := range pattern {
+		for j := 0; j < int(element-'0')*width; j++ {
+			*bars = append(*bars, i%2 == 0)
+		}
+	}
+}
+
+// eanLeftOdd holds the odd parity (L) patterns of the digits of UPC and
+// EAN barcodes. Even parity (G) patterns are these reversed and
+// inverted, and right hand (R) patterns are these inverted.
+var eanLeftOdd = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+
+// ean13Parities holds the parity of the six left hand digits of an
+// EAN-13 barcode, selected by its first digit, where 'G' is even.
+var ean13Parities = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+
+// upcEParities holds the parity of the six digits of a UPC-E barcode in
+// number system 0, selected by its check digit.
+var upcEParities = [10]string{"GGGLLL", "GGLGLL", "GGLLGL", "GGLLLG", "GLGGLL", "GLLGGL", "GLLLGG", "GLGLGL", "GLGLLG", "GLLGLG"}
+
+// eanDigit returns the modules of the digit in the parity: L, G or R.
+func eanDigit(digit byte, parity byte) string {
+	pattern := eanLeftOdd[digit-'0']
+	if parity == 'L' {
+		return pattern
+	}
+
+	inverted := []byte(pattern)
+	for i := range inverted {
+		inverted[i] ^= '0' ^ '1'
+	}
+	if parity == 'G' {
+		for i, j := 0, len(inverted)-1; i < j; i, j = i+1, j-1 {
+			inverted[i], inverted[j] = inverted[j], inverted[i]
+		}
+	}
+	return string(inverted)
+}
+
+// code39Patterns holds the narrow and wide elements of each CODE39
+// character, with '*' the start and stop character.
+var code39Patterns = map[byte]string{
+	'0': "nnnwwnwnn", '1': "wnnwnnnnw", '2': "nnwwnnnnw", '3': "wnwwnnnnn", '4': "nnnwwnnnw",
+	'5': "wnnwwnnnn", '6': "nnwwwnnnn", '7': "nnnwnnwnw", '8': "wnnwnnwnn", '9': "nnwwnnwnn",
+	'A': "wnnnnwnnw", 'B': "nnwnnwnnw", 'C': "wnwnnwnnn", 'D': "nnnnwwnnw", 'E': "wnnnwwnnn",
+	'F': "nnwnwwnnn", 'G': "nnnnnwwnw", 'H': "wnnnnwwnn", 'I': "nnwnnwwnn", 'J': "nnnnwwwnn",
+	'K': "wnnnnnnww", 'L': "nnwnnnnww", 'M': "wnwnnnnwn", 'N': "nnnnwnnww", 'O': "wnnnwnnwn",
+	'P': "nnwnwnnwn", 'Q': "nnnnnnwww", 'R': "wnnnnnwwn", 'S': "nnwnnnwwn", 'T': "nnnnwnwwn",
+	'U': "wwnnnnnnw", 'V': "nwwnnnnnw", 'W': "wwwnnnnnn", 'X': "nwnnwnnnw", 'Y': "wwnnwnnnn",
+	'Z': "nwwnwnnnn", '-': "nwnnnnwnw", '.': "wwnnnnwnn", ' ': "nwwnnnwnn", '$': "nwnwnwnnn",
+	'/': "nwnwnnnwn", '+': "nwnnnwnwn", '%': "nnnwnwnwn", '*': "nwnnwnwnn",
+}
+
+// itfPatterns holds the narrow and wide elements of each ITF digit.
+var itfPatterns = [10]string{"nnwwn", "wnnnw", "nwnnw", "wwnnn", "nnwnw", "wnwnn", "nwwnn", "nnnww", "wnnwn", "nwnwn"}
+
+// codabarPatterns holds the narrow and wide elements of each CODABAR
+// character.
+var codabarPatterns = map[byte]string{
+	'0': "nnnnnww", '1': "nnnnwwn", '2': "nnnwnnw", '3': "wwnnnnn", '4': "nnwnnwn",
+	'5': "wnnnnwn", '6': "nwnnnnw", '7': "nwnnwnn", '8': "nwwnnnn", '9': "wnnwnnn",
+	'-': "nnnwwnn", '$': "nnwwnnn", ':': "wnnnwnw", '/': "wnwnnnw", '.': "wnwnwnn",
+	'+': "nnwnwnw", 'A': "nnwwnwn", 'B': "nwnwnnw", 'C': "nnnwnww", 'D': "nnnwwwn",
+}
+
+// code93Charset holds the CODE93 characters in order of their values,
+// where a, b, c and d stand for the shift characters ($), (%), (/) and
+// (+), and '*' is the start and stop character.
+const code93Charset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%abcd*"
+
+// code93Patterns holds the 9 modules of each CODE93 character, most
+// significant bit first, in the order of code93Charset.
+var code93Patterns = [48]int{
+	0x114, 0x148, 0x144, 0x142, 0x128, 0x124, 0x122, 0x150, 0x112, 0x10A,
+	0x1A8, 0x1A4, 0x1A2, 0x194, 0x192, 0x18A, 0x168, 0x164, 0x162, 0x134,
+	0x11A, 0x158, 0x14C, 0x146, 0x12C, 0x116, 0x1B4, 0x1B2, 0x1AC, 0x1A6,
+	0x196, 0x19A, 0x16C, 0x166, 0x136, 0x13A, 0x12E, 0x1D4, 0x1D2, 0x1CA,
+	0x16E, 0x176, 0x1AE, 0x126, 0x1DA, 0x1D6, 0x132, 0x15E,
+}
+
+// code128Patterns holds the bar and space widths of each CODE128 symbol
+// value, with the stop character last.
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// renderBarcode draws the barcode for the data in the symbology of the
+// config, with the bars as tall as the config height. The human
+// readable interpretation of the data is returned with it, including
+// any check digit calculated for UPC and EAN barcodes.
+func renderBarcode(data string, cfg *BarcodeConfig) (*Bitmap, string, error) {
+	if err := validateBarcodeData(data, cfg); err != nil {
+		return nil, "", err
+	}
+	if cfg.width < 1 || cfg.width > 6 {
+		return nil, "", invalidConfig("invalid width option in BarcodeConfig: %v", cfg.width)
+	}
+	if cfg.height < 1 {
+		return nil, "", invalidConfig("invalid height option in BarcodeConfig: %v", cfg.height)
+	}
+
+	bars, hri := encodeBarcode(data, cfg.symbology, int(cfg.width))
+
+	// Control characters in the data are printed as spaces, so they
+	// cannot be mistaken for commands.
+	hri = strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7F {
+			return ' '
+		}
+		return r
+	}, hri)
+
+	bitmap := NewBitmap(len(bars), int(cfg.height))
+	for x, bar := range bars {
+		for y := 0; y < bitmap.Height; y++ {
+			bitmap.SetBlack(x, y, bar)
+		}
+	}
+
+	return bitmap, hri, nil
+}
+
+// encodeBarcode encodes data which has been validated for the
+// symbology into the dots across the barcode, for the module width in
+// dots. Wide elements of CODE39, ITF and CODABAR barcodes are 2.5 times
+// the module width.
+func encodeBarcode(data string, symbology string, width int) (barcodeBars, string) {
+	var bars barcodeBars
+	wide := width * 5 / 2
+
+	switch symbology {
+	case "UPC-A", "EAN-13":
+		digits := data
+		if symbology == "UPC-A" {
+			digits = "0" + data
+		}
+		if len(digits) == 12 {
+			digits += string(checkDigit(digits))
+		}
+		bars.modules("101", width)
+		for i, parity := range []byte(ean13Parities[digits[0]-'0']) {
+			bars.modules(eanDigit(digits[1+i], parity), width)
+		}
+		bars.modules("01010", width)
+		for i := 7; i < 13; i++ {
+			bars.modules(eanDigit(digits[i], 'R'), width)
+		}
+		bars.modules("101", width)
+		if symbology == "UPC-A" {
+			return bars, digits[1:]
+		}
+		return bars, digits
+	case "EAN-8":
+		digits := data
+		if len(digits) == 7 {
+			digits += string(checkDigit(digits))
+		}
+		bars.modules("101", width)
+		for i := 0; i < 4; i++ {
+			bars.modules(eanDigit(digits[i], 'L'), width)
+		}
+		bars.modules("01010", width)
+		for i := 4; i < 8; i++ {
+			bars.modules(eanDigit(digits[i], 'R'), width)
+		}
+		bars.modules("101", width)
+		return bars, digits
+	case "UPC-E":
+		digits := data
+		switch len(data) {
+		case 7, 8:
+			digits = data[1:7]
+		case 11, 12:
+			digits, _ = compressUpcA(data[:11])
+		}
+		check := checkDigit(expandUpcE(digits))
+		bars.modules("101", width)
+		for i, parity := range []byte(upcEParities[check-'0']) {
+			bars.modules(eanDigit(digits[i], parity), width)
+		}
+		bars.modules("010101", width)
+		return bars, "0" + digits + string(check)
+	case "CODE39":
+		trimmed := strings.TrimSuffix(strings.TrimPrefix(data, "*"), "*")
+		for _, b := range []byte("*" + trimmed + "*") {
+			bars.elements(code39Patterns[b], width, wide)
+			bars.modules("0", width)
+		}
+		return bars[:len(bars)-width], data
+	case "ITF":
+		bars.elements("nnnn", width, wide)
+		for i := 0; i < len(data); i += 2 {
+			first, second := itfPatterns[data[i]-'0'], itfPatterns[data[i+1]-'0']
+			for j := 0; j < 5; j++ {
+				bars.elements(first[j:j+1]+second[j:j+1], width, wide)
+			}
+		}
+		bars.elements("wnn", width, wide)
+		return bars, data
+	case "CODABAR":
+		for _, b := range []byte(strings.ToUpper(data)) {
+			bars.elements(codabarPatterns[b], width, wide)
+			bars.modules("0", width)
+		}
+		return bars[:len(bars)-width], data
+	case "CODE93":
+		return encodeCode93(data, width), data
+	default:
+		chars, _ := optimiseCode128(data, symbology)
+		checksum := 0
+		for i, char := range chars {
+			value := char.value(i == 0)
+			bars.widths(code128Patterns[value], width)
+			checksum += value * max(i, 1)
+		}
+		bars.widths(code128Patterns[checksum%103], width)
+		bars.widths(code128Patterns[106], width)
+		return bars, data
+	}
+}
+
+// encodeCode93 encodes ASCII data as a CODE93 barcode, using shift
+// characters for those outside the CODE93 character set, followed by
+// the two check characters.
+func encodeCode93(data string, width int) barcodeBars {
+	var values []int
+	for _, b := range []byte(code93FullAscii(data)) {
+		values = append(values, strings.IndexByte(code93Charset, b))
+	}
+
+	for _, maxWeight := range []int{20, 15} {
+		sum := 0
+		for i := range values {
+			sum += values[len(values)-1-i] * (i%maxWeight + 1)
+		}
+		values = append(values, sum%47)
+	}
+
+	var bars barcodeBars
+	start := strings.IndexByte(code93Charset, '*')
+	for _, value := range append(append([]int{start}, values...), start) {
+		for i := 8; i >= 0; i-- {
+			bars.modules(string('0'+byte(code93Patterns[value]>>i&1)), width)
+		}
+	}
+	bars.modules("1", width)
+
+	return bars
+}
+
+// code93FullAscii converts ASCII data into CODE93 characters, replacing
+// characters outside the character set with a shift character, written
+// as a, b, c or d, and a letter.
+func code93FullAscii(data string) string {
+	var converted strings.Builder
+	for _, b := range []byte(data) {
+		switch {
+		case b == 0:
+			converted.WriteString("bU")
+		case b <= 26:
+			converted.WriteString("a" + string('A'+b-1))
+		case b <= 31:
+			converted.WriteString("b" + string('A'+b-27))
+		case b == ' ' || b == '$' || b == '%' || b == '+':
+			converted.WriteByte(b)
+		case b <= ',':
+			converted.WriteString("c" + string('A'+b-'!'))
+		case b <= '9':
+			converted.WriteByte(b)
+		case b == ':':
+			converted.WriteString("cZ")
+		case b <= '?':
+			converted.WriteString("b" + string('F'+b-';'))
+		case b == '@':
+			converted.WriteString("bV")
+		case b <= 'Z':
+			converted.WriteByte(b)
+		case b <= '_':
+			converted.WriteString("b" + string('K'+b-'['))
+		case b == '`':
+			converted.WriteString("bW")
+		case b <= 'z':
+			converted.WriteString("d" + string('A'+b-'a'))
+		default:
+			converted.WriteString("b" + string('P'+b-'{'))
+		}
+	}
+	return converted.String()
+}
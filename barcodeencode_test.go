@@ -0,0 +1,113 @@
+package escpos
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCode128Patterns(t *testing.T) {
+	seen := map[string]bool{}
+
+	for value, pattern := range code128Patterns[:106] {
+		modules, barModules := 0, 0
+		for i, width := range pattern {
+			modules += int(width - '0')
+			if i%2 == 0 {
+				barModules += int(width - '0')
+			}
+		}
+
+		if modules != 11 || barModules%2 != 0 || seen[pattern] {
+			t.Errorf("code128Patterns has invalid pattern for value %v: %v", value, pattern)
+		}
+		seen[pattern] = true
+	}
+}
+
+func TestEncodeBarcode(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        string
+		symbology   string
+		wantModules int
+		wantHri     string
+	}{
+		{"UPC-A adds check digit", "03600029145", "UPC-A", 95, "036000291452"},
+		{"EAN-13 keeps check digit", "4006381333931", "EAN-13", 95, "4006381333931"},
+		{"EAN-8 adds check digit", "9638507", "EAN-8", 67, "96385074"},
+		{"UPC-E is expanded with number system and check digit", "425261", "UPC-E", 51, "04252614"},
+		{"UPC-E is compressed from UPC-A", "01234500006", "UPC-E", 51, "01234565"},
+		{"CODE128 matches the module count", "Hello123456", "CODE128", 134, "Hello123456"},
+		{"CODE93 has start, check and stop characters", "TEST93", "CODE93", 9*10 + 1, "TEST93"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			bars, hri := encodeBarcode(testCase.data, testCase.symbology, 2)
+
+			if len(bars) != testCase.wantModules*2 || hri != testCase.wantHri {
+				t.Errorf("encodeBarcode did not return expected barcode: wanted %v dots and HRI %q, got %v dots and HRI %q", testCase.wantModules*2, testCase.wantHri, len(bars), hri)
+			}
+
+			if !bars[0] || !bars[len(bars)-1] {
+				t.Errorf("encodeBarcode did not start and end with a bar")
+			}
+		})
+	}
+
+	t.Run("CODE39 uses narrow and wide elements with narrow gaps", func(t *testing.T) {
+		bars, _ := encodeBarcode("A", "CODE39", 2)
+
+		var got strings.Builder
+		for _, bar := range bars {
+			if bar {
+				got.WriteByte('1')
+			} else {
+				got.WriteByte('0')
+			}
+		}
+
+		// *A* with narrow elements of 2 dots and wide elements of 5.
+		want := "110000011001111100111110011" + "00" + "111110011001100000110011111" + "00" + "110000011001111100111110011"
+
+		if got.String() != want {
+			t.Errorf("encodeBarcode did not return expected bars: wanted %v, got %v", want, got.String())
+		}
+	})
+}
+
+func TestRenderBarcode(t *testing.T) {
+	t.Run("bars are as tall as the height", func(t *testing.T) {
+		cfg := DefaultBarcodeConfig().Symbology("EAN-8").Width(2).Height(40)
+
+		got, _, err := renderBarcode("9638507", &cfg)
+
+		if err != nil {
+			t.Fatalf("renderBarcode returned error: %v", err)
+		}
+
+		if got.Width != 134 || got.Height != 40 || !got.Black(0, 39) || got.Black(2, 0) {
+			t.Errorf("renderBarcode did not return expected bitmap, got %vx%v", got.Width, got.Height)
+		}
+	})
+
+	t.Run("control characters are printed as spaces in the HRI", func(t *testing.T) {
+		cfg := DefaultBarcodeConfig()
+
+		_, hri, err := renderBarcode("A\x1B@B", &cfg)
+
+		if err != nil || hri != "A @B" {
+			t.Errorf("renderBarcode did not return expected HRI: wanted %q, got %q (%v)", "A @B", hri, err)
+		}
+	})
+
+	t.Run("invalid data returns config error", func(t *testing.T) {
+		cfg := DefaultBarcodeConfig().Symbology("EAN-8")
+
+		got, _, err := renderBarcode("12", &cfg)
+
+		if got != nil || err == nil {
+			t.Errorf("renderBarcode did not return error for invalid data")
+		}
+	})
+}
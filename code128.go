@@ -0,0 +1,300 @@
+package escpos
+
+import "strings"
+
+// code128Fnc1 marks the position of an FNC1 character in the units
+// passed to encodeCode128.
+const code128Fnc1 = -1
+
+// code128Char is a single CODE128 symbol character. A switch selects
+// the code set, with the first switch of a symbol being its start
+// character. Data holds the ASCII byte for code sets A and B, and the
+// number 0 to 99 for code set C.
+type code128Char struct {
+	codeSet  byte
+	isSwitch bool
+	fnc1     bool
+	data     byte
+}
+
+// value returns the CODE128 symbol value of the character, where a
+// switch is a start character if it is the first in the symbol.
+func (char code128Char) value(first bool) int {
+	switch {
+	case char.isSwitch && first:
+		return 103 + int(char.codeSet-'A')
+	case char.isSwitch:
+		return map[byte]int{'A': 101, 'B': 100, 'C': 99}[char.codeSet]
+	case char.fnc1:
+		return 102
+	case char.codeSet == 'C':
+		return int(char.data)
+	case char.data < 32:
+		return int(char.data) + 64
+	default:
+		return int(char.data) - 32
+	}
+}
+
+// code128Sets is the order code sets are tried in, which decides
+// between equally short encodings.
+var code128Sets = []byte{'B', 'C', 'A'}
+
+// code128Encodes reports how many units starting at i a single character
+// of the code set encodes, or 0 if it cannot encode them.
+func code128Encodes(units []int, i int, codeSet byte) int {
+	unit := units[i]
+	switch {
+	case unit == code128Fnc1:
+		return 1
+	case codeSet == 'A' && unit < 96:
+		return 1
+	case codeSet == 'B' && unit >= 32:
+		return 1
+	case codeSet == 'C' && i+1 < len(units) && isDigitUnit(unit) && isDigitUnit(units[i+1]):
+		return 2
+	default:
+		return 0
+	}
+}
+
+func isDigitUnit(unit int) bool {
+	return unit >= '0' && unit <= '9'
+}
+
+// encodeCode128 encodes the units, which are ASCII bytes or
+// code128Fnc1, into the shortest sequence of CODE128 symbol characters,
+// switching between code sets A, B and C as needed.
+func encodeCode128(units []int) ([]code128Char, error) {
+	const unencodable = 1 << 30
+	n := len(units)
+
+	// stay[i][s] is the fewest characters encoding units[i:] when the
+	// next character is in code set s, and best[i][s] is the same when a
+	// switch away from s is allowed first.
+	stay := make([]map[byte]int, n+1)
+	best := make([]map[byte]int, n+1)
+	for i := n; i >= 0; i-- {
+		stay[i] = map[byte]int{}
+		best[i] = map[byte]int{}
+		for _, codeSet := range code128Sets {
+			if i == n {
+				stay[i][codeSet] = 0
+			} else if consumed := code128Encodes(units, i, codeSet); consumed > 0 {
+				stay[i][codeSet] = 1 + best[i+consumed][codeSet]
+			} else {
+				stay[i][codeSet] = unencodable
+			}
+		}
+		for _, codeSet := range code128Sets {
+			best[i][codeSet] = stay[i][codeSet]
+			for _, other := range code128Sets {
+				best[i][codeSet] = min(best[i][codeSet], 1+stay[i][other])
+			}
+		}
+	}
+
+	codeSet := code128Sets[0]
+	for _, candidate := range code128Sets {
+		if stay[0][candidate] < stay[0][codeSet] {
+			codeSet = candidate
+		}
+	}
+	if n == 0 || stay[0][codeSet] >= unencodable {
+		return nil, invalidConfig("invalid data for CODE128 barcode, expected ASCII")
+	}
+
+	chars := []code128Char{{codeSet: codeSet, isSwitch: true}}
+	for i := 0; i < n; {
+		if stay[i][codeSet] > best[i][codeSet] {
+			for _, other := range code128Sets {
+				if 1+stay[i][other] == best[i][codeSet] {
+					codeSet = other
+					break
+				}
+			}
+			chars = append(chars, code128Char{codeSet: codeSet, isSwitch: true})
+		}
+
+		consumed := code128Encodes(units, i, codeSet)
+		switch {
+		case units[i] == code128Fnc1:
+			chars = append(chars, code128Char{codeSet: codeSet, fnc1: true})
+		case codeSet == 'C':
+			chars = append(chars, code128Char{codeSet: codeSet, data: byte((units[i]-'0')*10 + units[i+1] - '0')})
+		default:
+			chars = append(chars, code128Char{codeSet: codeSet, data: byte(units[i])})
+		}
+		i += consumed
+	}
+
+	return chars, nil
+}
+
+// code128Units converts CODE128 data, or GS1-128 data in bracketed
+// Application Identifier syntax, into the units passed to
+// encodeCode128.
+func code128Units(data string, symbology string) ([]int, error) {
+	if symbology == "GS1-128" {
+		return gs1Units(data)
+	}
+
+	units := make([]int, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] > 127 {
+			return nil, invalidConfig("invalid data for CODE128 barcode, expected ASCII: %q", data)
+		}
+		units[i] = int(data[i])
+	}
+	return units, nil
+}
+
+// code128Modules returns the width in modules of the bars of a CODE128
+// symbol: 11 for each character including the check character, and 13
+// for the stop character.
+func code128Modules(chars []code128Char) int {
+	return 11*(len(chars)+1) + 13
+}
+
+// optimiseCode128 encodes CODE128 or GS1-128 data into the shortest
+// sequence of symbol characters.
+func optimiseCode128(data string, symbology string) ([]code128Char, error) {
+	units, err := code128Units(data, symbology)
+	if err != nil {
+		return nil, err
+	}
+	return encodeCode128(units)
+}
+
+// gs1FixedLengths holds the total length, including the Application
+// Identifier, of elements whose AI starts with the given two digits and
+// which have a predefined length. Other elements are variable length
+// and need an FNC1 separator when followed by another element.
+var gs1FixedLengths = map[string]int{
+	"00": 20, "01": 16, "02": 16, "03": 16, "04": 18,
+	"11": 8, "12": 8, "13": 8, "14": 8, "15": 8, "16": 8, "17": 8, "18": 8, "19": 8,
+	"20": 4,
+	"31": 10, "32": 10, "33": 10, "34": 10, "35": 10, "36": 10,
+	"41": 16,
+}
+
+// gs1CheckDigitAIs are Application Identifiers whose value ends with a
+// modulo 10 check digit.
+var gs1CheckDigitAIs = map[string]bool{"00": true, "01": true, "02": true}
+
+// gs1Element is a single Application Identifier and its value.
+type gs1Element struct {
+	ai    string
+	value string
+}
+
+// parseGs1 parses GS1 data in bracketed Application Identifier syntax,
+// such as (01)09501101530003(17)250101(10)ABC123, validating the length
+// of elements with a predefined length and any check digits.
+func parseGs1(data string) ([]gs1Element, error) {
+	var elements []gs1Element
+
+	rest := data
+	for rest != "" {
+		if rest[0] != '(' {
+			return nil, invalidConfig("invalid GS1 data, expected (AI) at %q", rest)
+		}
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return nil, invalidConfig("invalid GS1 data, unterminated AI in %q", rest)
+		}
+
+		ai := rest[1:end]
+		rest = rest[end+1:]
+		if len(ai) < 2 || len(ai) > 4 || !isDigits(ai) {
+			return nil, invalidConfig("invalid GS1 Application Identifier: %q", ai)
+		}
+
+		next := strings.IndexByte(rest, '(')
+		if next < 0 {
+			next = len(rest)
+		}
+		value := rest[:next]
+		rest = rest[next:]
+
+		if value == "" {
+			return nil, invalidConfig("invalid GS1 data, empty value for AI (%v)", ai)
+		}
+		for i := 0; i < len(value); i++ {
+			if value[i] <= ' ' || value[i] > '~' {
+				return nil, invalidConfig("invalid GS1 data, unsupported character in value for AI (%v): %q", ai, value)
+			}
+		}
+		if length, ok := gs1FixedLengths[ai[:2]]; ok && len(ai)+len(value) != length {
+			return nil, invalidConfig("invalid GS1 data, expected %v characters for AI (%v): %q", length-len(ai), ai, value)
+		}
+		if gs1CheckDigitAIs[ai] {
+			if !isDigits(value) || checkDigit(value[:len(value)-1]) != value[len(value)-1] {
+				return nil, invalidConfig("invalid check digit for GS1 AI (%v): %q", ai, value)
+			}
+		}
+
+		elements = append(elements, gs1Element{ai, value})
+	}
+
+	if len(elements) == 0 {
+		return nil, invalidConfig("invalid GS1 data, expected at least one AI")
+	}
+
+	return elements, nil
+}
+
+// gs1Units converts GS1 data into the units of a GS1-128 symbol, which
+// starts with FNC1 and separates variable length elements with FNC1.
+func gs1Units(data string) ([]int, error) {
+	elements, err := parseGs1(data)
+	if err != nil {
+		return nil, err
+	}
+
+	units := []int{code128Fnc1}
+	for i, element := range elements {
+		for _, b := range []byte(element.ai + element.value) {
+			units = append(units, int(b))
+		}
+		if _, fixed := gs1FixedLengths[element.ai[:2]]; !fixed && i < len(elements)-1 {
+			units = append(units, code128Fnc1)
+		}
+	}
+
+	dataLength := 0
+	for _, unit := range units {
+		if unit != code128Fnc1 {
+			dataLength++
+		}
+	}
+	if dataLength > 48 {
+		return nil, invalidConfig("maximum GS1-128 data length exceeded: %v > 48 (max)", dataLength)
+	}
+
+	return units, nil
+}
+
+// gs1Separator is the GS character, which stands for FNC1 after
+// variable length elements in a GS1 element string.
+const gs1Separator = "\x1D"
+
+// gs1ElementString converts GS1 data in bracketed Application
+// Identifier syntax into an element string, with elements of variable
+// length followed by gs1Separator unless they are last.
+func gs1ElementString(data string) (string, error) {
+	elements, err := parseGs1(data)
+	if err != nil {
+		return "", err
+	}
+
+	var elementString string
+	for i, element := range elements {
+		elementString += element.ai + element.value
+		if _, fixed := gs1FixedLengths[element.ai[:2]]; !fixed && i < len(elements)-1 {
+			elementString += gs1Separator
+		}
+	}
+
+	return elementString, nil
+}
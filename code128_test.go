@@ -0,0 +1,79 @@
+package escpos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOptimiseCode128(t *testing.T) {
+	cases := []struct {
+		name      string
+		symbology string
+		data      string
+		want      string
+	}{
+		{"text uses code set B", "CODE128", "Hello", "{BHello"},
+		{"even digits use code set C", "CODE128", "123456", "{C\x0C\x22\x38"},
+		{"odd digits switch code set once", "CODE128", "12345", "{B1{C\x17\x2D"},
+		{"short digit runs stay in code set B", "CODE128", "AB12CD", "{BAB12CD"},
+		{"long digit runs switch to code set C", "CODE128", "ORD12345678", "{BORD{C\x0C\x22\x38\x4E"},
+		{"control characters use code set A", "CODE128", "AB\tC", "{AAB\tC"},
+		{"braces are escaped in code set B", "CODE128", "{x}", "{B{{x}"},
+		{"fixed length AIs are not separated", "GS1-128", "(01)09501101530003(17)250101", "{C{1\x01\x09\x32\x0B\x01\x35\x00\x03\x11\x19\x01\x01"},
+		{"variable length AIs are separated by FNC1", "GS1-128", "(10)AB12(21)7", "{B{110AB12{1217"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			chars, err := optimiseCode128(testCase.data, testCase.symbology)
+
+			if err != nil {
+				t.Fatalf("optimiseCode128 returned error: %v", err)
+			}
+
+			got := epsonCode128Data(chars)
+
+			if got != testCase.want {
+				t.Errorf("optimiseCode128 did not return expected encoding: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestParseGs1(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"missing brackets returns error", "0109501101530003"},
+		{"unterminated AI returns error", "(01"},
+		{"non-numeric AI returns error", "(0A)123"},
+		{"empty value returns error", "(10)(21)123"},
+		{"wrong fixed length returns error", "(17)2501"},
+		{"wrong check digit returns error", "(01)09501101530004"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := parseGs1(testCase.data)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("parseGs1 did not return config error, got %v", err)
+			}
+		})
+	}
+
+	t.Run("elements are parsed", func(t *testing.T) {
+		got, err := parseGs1("(01)09501101530003(10)ABC123")
+
+		if err != nil {
+			t.Fatalf("parseGs1 returned error: %v", err)
+		}
+
+		want := []gs1Element{{"01", "09501101530003"}, {"10", "ABC123"}}
+
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("parseGs1 did not return expected elements: wanted %v, got %v", want, got)
+		}
+	})
+}
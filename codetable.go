@@ -0,0 +1,332 @@
+package escpos
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// codeTable encodes characters into the bytes the printer prints them
+// from, in a character code table or a multi-byte encoding.
+type codeTable interface {
+	EncodeRune(r rune) (string, bool)
+}
+
+// singleByteTable is a single byte character code table.
+type singleByteTable struct {
+	charmap *charmap.Charmap
+}
+
+func (table singleByteTable) EncodeRune(r rune) (string, bool) {
+	b, ok := table.charmap.EncodeRune(r)
+	return string([]byte{b}), ok
+}
+
+// katakanaTable is the half-width katakana code table of JIS X 0201,
+// with ASCII in the lower half.
+type katakanaTable struct{}
+
+func (katakanaTable) EncodeRune(r rune) (string, bool) {
+	switch {
+	case r < utf8.RuneSelf:
+		return string([]byte{byte(r)}), true
+	case r >= '｡' && r <= 'ﾟ':
+		return string([]byte{byte(r - '｡' + 0xA1)}), true
+	default:
+		return "", false
+	}
+}
+
+// multiByteTable is a multi-byte character encoding, printed in the
+// printer's Kanji or Chinese character mode, with ASCII as single bytes.
+// The encoder is shared by every Client, so it is guarded by a mutex.
+type multiByteTable struct {
+	mu      sync.Mutex
+	encoder *encoding.Encoder
+}
+
+func newMultiByteTable(enc encoding.Encoding) *multiByteTable {
+	return &multiByteTable{encoder: enc.NewEncoder()}
+}
+
+func (table *multiByteTable) EncodeRune(r rune) (string, bool) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	encoded, err := table.encoder.String(string(r))
+	// Printers only print single and double byte characters, so the
+	// four byte sequences of GB18030, which hold characters such as
+	// emoji, are as unprintable as characters the encoding lacks.
+	return encoded, err == nil && len(encoded) <= 2
+}
+
+// codeTables holds the character code tables the Client can transcode
+// text into, by the names profiles use for them.
+var codeTables = map[string]codeTable{
+	"CP437":      singleByteTable{charmap.CodePage437},
+	"CP850":      singleByteTable{charmap.CodePage850},
+	"CP852":      singleByteTable{charmap.CodePage852},
+	"CP858":      singleByteTable{charmap.CodePage858},
+	"CP860":      singleByteTable{charmap.CodePage860},
+	"CP863":      singleByteTable{charmap.CodePage863},
+	"CP865":      singleByteTable{charmap.CodePage865},
+	"CP866":      singleByteTable{charmap.CodePage866},
+	"Katakana":   katakanaTable{},
+	"ISO8859-2":  singleByteTable{charmap.ISO8859_2},
+	"ISO8859-15": singleByteTable{charmap.ISO8859_15},
+	"WPC1250":    singleByteTable{charmap.Windows1250},
+	"WPC1251":    singleByteTable{charmap.Windows1251},
+	"WPC1252":    singleByteTable{charmap.Windows1252},
+	"WPC1253":    singleByteTable{charmap.Windows1253},
+	"WPC1254":    singleByteTable{charmap.Windows1254},
+	"WPC1255":    singleByteTable{charmap.Windows1255},
+	"WPC1256":    singleByteTable{charmap.Windows1256},
+	"WPC1257":    singleByteTable{charmap.Windows1257},
+	"WPC1258":    singleByteTable{charmap.Windows1258},
+}
+
+// multiByteTables holds the multi-byte character encodings the Client
+// can transcode text into, by the names profiles use for them.
+var multiByteTables = map[string]codeTable{
+	"Shift-JIS": newMultiByteTable(japanese.ShiftJIS),
+	"GB18030":   newMultiByteTable(simplifiedchinese.GB18030),
+	"Big5":      newMultiByteTable(traditionalchinese.Big5),
+	"KS C 5601": newMultiByteTable(korean.EUCKR),
+}
+
+// internationalPositions are the ASCII characters which an
+// international character set replaces, in the order the sets in
+// internationalCharacterSets list their replacements.
+const internationalPositions = "#$@[\\]^`{|}~"
+
+// internationalCharacterSets holds the characters each international
+// character set selected with ESC R prints in internationalPositions.
+var internationalCharacterSets = map[string]string{
+	"USA":              "#$@[\\]^`{|}~",
+	"France":           "#$à°ç§^`éùè¨",
+	"Germany":          "#$§ÄÖÜ^`äöüß",
+	"UK":               "£$@[\\]^`{|}~",
+	"Denmark I":        "#$@ÆØÅ^`æøå~",
+	"Sweden":           "#¤ÉÄÖÅÜéäöåü",
+	"Italy":            "#$@°\\é^ùàòèì",
+	"Spain I":          "₧$@¡Ñ¿^`¨ñ}~",
+	"Japan":            "#$@[¥]^`{|}~",
+	"Norway":           "#¤ÉÆØÅÜéæøåü",
+	"Denmark II":       "#$ÉÆØÅÜéæøåü",
+	"Spain II":         "#$á¡Ñ¿é`íñóú",
+	"Latin America":    "#$á¡Ñ¿éüíñóú",
+	"Korea":            "#$@[₩]^`{|}~",
+	"Slovenia/Croatia": "#$ŽŠĐĆČžšđćč",
+	"China":            "#¥@[\\]^`{|}~",
+	"Vietnam":          "#₫@[\\]^`{|}~",
+}
+
+// internationalTable is a code table with the ASCII characters in
+// internationalPositions replaced by those of an international
+// character set.
+type internationalTable struct {
+	codeTable
+	replacements []rune
+}
+
+func (table internationalTable) EncodeRune(r rune) (string, bool) {
+	for i, replacement := range table.replacements {
+		if replacement == r {
+			return internationalPositions[i : i+1], true
+		}
+	}
+	if strings.ContainsRune(internationalPositions, r) {
+		return "", false
+	}
+	return table.codeTable.EncodeRune(r)
+}
+
+// replacementChar is printed in place of characters which cannot be
+// encoded, under the replace fallback.
+const replacementChar = '?'
+
+// transliterations holds ASCII stand-ins for common characters which
+// do not decompose into an ASCII letter and accents.
+var transliterations = map[rune]string{
+	'€': "EUR", '£': "GBP", '¥': "JPY", '¢': "c",
+	'‘': "'", '’': "'", '‚': ",", '“': "\"", '”': "\"", '„': "\"", '«': "<<", '»': ">>",
+	'–': "-", '—': "-", '…': "...", '•': "*", '·': ".", '×': "x", '÷': "/",
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'þ': "th", 'Þ': "Th", 'ð': "d", 'Ð': "D",
+	'©': "(c)", '®': "(R)", '™': "TM", '°': "o", ' ': " ",
+}
+
+// transliterate returns a plainer spelling of the character, built
+// from characters the code table can encode, if one is known.
+func transliterate(r rune, table codeTable) (string, bool) {
+	candidate, ok := transliterations[r]
+	if !ok {
+		// Decomposing the character and dropping its combining marks
+		// leaves the base letter, so é becomes e.
+		var stripped strings.Builder
+		for _, decomposed := range norm.NFD.String(string(r)) {
+			if !unicode.Is(unicode.Mn, decomposed) {
+				stripped.WriteRune(decomposed)
+			}
+		}
+		candidate = stripped.String()
+		if candidate == string(r) || candidate == "" {
+			return "", false
+		}
+	}
+
+	for _, c := range candidate {
+		if _, ok := table.EncodeRune(c); !ok {
+			return "", false
+		}
+	}
+	return candidate, true
+}
+
+// encodeRune encodes the character into the code table, applying the
+// fallback if the table cannot encode it.
+func encodeRune(encoded *strings.Builder, r rune, name string, table codeTable, fallback string) error {
+	if b, ok := table.EncodeRune(r); ok {
+		encoded.WriteString(b)
+		return nil
+	}
+
+	switch fallback {
+	case "transliterate":
+		if candidate, ok := transliterate(r, table); ok {
+			for _, c := range candidate {
+				b, _ := table.EncodeRune(c)
+				encoded.WriteString(b)
+			}
+			return nil
+		}
+		encoded.WriteByte(replacementChar)
+	case "error":
+		return &EncodingError{Char: r, CodeTable: name}
+	default:
+		encoded.WriteByte(replacementChar)
+	}
+	return nil
+}
+
+// planCodeTables chooses the code table each character is encoded in,
+// as an index into tables, using as few switches as possible to start
+// from and return to the start table. A start of -1 means the active
+// table is unknown, so only characters which every table encodes alike,
+// such as ASCII, can be printed before a table is selected, and no
+// table is restored.
+// Characters which no table can encode stay in the current table. Ties
+// are broken by switching as late as possible, to the earliest table.
+func planCodeTables(runes []rune, tables []codeTable, start int) []int {
+	// States 0 to len(tables)-1 are the tables, and the state
+	// len(tables) is the unknown table before any is selected.
+	unknown := len(tables)
+	encodes := func(state int, r rune) bool {
+		if state != unknown {
+			_, ok := tables[state].EncodeRune(r)
+			return ok
+		}
+		b, ok := tables[0].EncodeRune(r)
+		for _, table := range tables[1:] {
+			other, otherOk := table.EncodeRune(r)
+			ok = ok && otherOk && other == b
+		}
+		return ok
+	}
+
+	encodable := make([]bool, len(runes))
+	for i, r := range runes {
+		for table := range tables {
+			encodable[i] = encodable[i] || encodes(table, r)
+		}
+	}
+
+	// switches[i][state] is the fewest switches needed to print
+	// runes[i:] and restore the start table, when in the state.
+	switches := make([][]int, len(runes)+1)
+	switches[len(runes)] = make([]int, unknown+1)
+	for state := range switches[len(runes)] {
+		if start >= 0 && state != start {
+			switches[len(runes)][state] = 1
+		}
+	}
+
+	const impossible = 1 << 30
+	for i := len(runes) - 1; i >= 0; i-- {
+		switches[i] = make([]int, unknown+1)
+		for state := range switches[i] {
+			switches[i][state] = impossible
+			if !encodable[i] || encodes(state, runes[i]) {
+				switches[i][state] = switches[i+1][state]
+			}
+			for table := range tables {
+				if encodes(table, runes[i]) {
+					switches[i][state] = min(switches[i][state], 1+switches[i+1][table])
+				}
+			}
+		}
+	}
+
+	state := start
+	if state < 0 {
+		state = unknown
+	}
+
+	plan := make([]int, len(runes))
+	for i, r := range runes {
+		stay := !encodable[i] || encodes(state, r)
+		if !stay || switches[i+1][state] != switches[i][state] {
+			for table := range tables {
+				if encodes(table, r) && 1+switches[i+1][table] == switches[i][state] {
+					state = table
+					break
+				}
+			}
+		}
+
+		plan[i] = state
+		if state == unknown {
+			plan[i] = -1
+		}
+	}
+
+	return plan
+}
+
+// TextWidth returns the number of character columns the text occupies
+// at the default character size. East Asian wide and fullwidth
+// characters, such as kanji and hangul, occupy two columns, while
+// control characters and combining marks occupy none.
+func TextWidth(text string) int {
+	columns := 0
+	for _, r := range text {
+		switch {
+		case unicode.IsControl(r) || unicode.Is(unicode.Mn, r):
+		case width.LookupRune(r).Kind() == width.EastAsianWide || width.LookupRune(r).Kind() == width.EastAsianFullwidth:
+			columns += 2
+		default:
+			columns++
+		}
+	}
+	return columns
+}
+
+// isASCII reports whether the text is entirely ASCII, which every code
+// table encodes unchanged.
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
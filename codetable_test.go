@@ -0,0 +1,183 @@
+package escpos
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEncodeRune(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		table    string
+		fallback string
+		want     string
+	}{
+		{"ASCII is unchanged", "Total: 10", "CP437", "replace", "Total: 10"},
+		{"accented letters are encoded", "café über", "CP437", "replace", "caf\x82 \x81ber"},
+		{"euro is encoded in CP858", "€5", "CP858", "replace", "\xD55"},
+		{"euro is encoded in WPC1252", "€5", "WPC1252", "replace", "\x805"},
+		{"cyrillic is encoded in CP866", "Привет", "CP866", "replace", "\x8F\xE0\xA8\xA2\xA5\xE2"},
+		{"half-width katakana is encoded", "ｶﾀｶﾅ", "Katakana", "replace", "\xB6\xC0\xB6\xC5"},
+		{"unencodable characters are replaced", "€5", "CP437", "replace", "?5"},
+		{"unknown fallback replaces", "€5", "CP437", "", "?5"},
+		{"euro is transliterated", "€5", "CP437", "transliterate", "EUR5"},
+		{"accents are removed when transliterating", "Łódź", "CP866", "transliterate", "Lodz"},
+		{"untransliterable characters are replaced", "中", "CP437", "transliterate", "?"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got strings.Builder
+			for _, r := range testCase.text {
+				if err := encodeRune(&got, r, testCase.table, codeTables[testCase.table], testCase.fallback); err != nil {
+					t.Fatalf("encodeRune returned error: %v", err)
+				}
+			}
+
+			if got.String() != testCase.want {
+				t.Errorf("encodeRune did not return expected bytes: wanted %q, got %q", testCase.want, got.String())
+			}
+		})
+	}
+
+	t.Run("error fallback returns encoding error", func(t *testing.T) {
+		var got strings.Builder
+		err := encodeRune(&got, '€', "CP437", codeTables["CP437"], "error")
+
+		var encodingErr *EncodingError
+		if !errors.Is(err, ErrUnencodable) || !errors.As(err, &encodingErr) || encodingErr.Char != '€' || encodingErr.CodeTable != "CP437" {
+			t.Errorf("encodeRune did not return expected error, got %v", err)
+		}
+
+		if got.Len() != 0 {
+			t.Errorf("encodeRune wrote bytes despite error, got %q", got.String())
+		}
+	})
+}
+
+func TestPlanCodeTables(t *testing.T) {
+	tables := []codeTable{codeTables["CP437"], codeTables["CP858"], codeTables["CP852"], codeTables["CP866"]}
+
+	cases := []struct {
+		name  string
+		text  string
+		start int
+		want  []int
+	}{
+		{"text the start table covers stays in it", "café", 0, []int{0, 0, 0, 0}},
+		{"switches happen as late as possible", "a€", 0, []int{0, 1}},
+		{"one table covering a run is preferred to several", "é€ä", -1, []int{1, 1, 1}},
+		{"each run gets a table", "łя", 0, []int{2, 3}},
+		{"ASCII before any table is selected stays unknown", "ab€", -1, []int{-1, -1, 1}},
+		{"unencodable characters stay in the current table", "中€", 0, []int{0, 1}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := planCodeTables([]rune(testCase.text), tables, testCase.start)
+
+			if !slices.Equal(got, testCase.want) {
+				t.Errorf("planCodeTables did not return expected plan: wanted %v, got %v", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestInternationalTable(t *testing.T) {
+	for set, replacements := range internationalCharacterSets {
+		t.Run(set+" replaces every position", func(t *testing.T) {
+			if got := utf8.RuneCountInString(replacements); got != len(internationalPositions) {
+				t.Errorf("international character set has %v replacements, wanted %v", got, len(internationalPositions))
+			}
+		})
+	}
+
+	cases := []struct {
+		name string
+		text string
+		set  string
+		want string
+	}{
+		{"pound is printed in place of hash", "£5", "UK", "#5"},
+		{"hash cannot be printed in UK", "#5", "UK", "?5"},
+		{"umlauts are printed in place of brackets", "Größe", "Germany", "Gr|~e"},
+		{"characters outside the set use the code table", "€5", "UK", "\xD55"},
+		{"USA leaves ASCII unchanged", "#$@", "USA", "#$@"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			table := internationalTable{codeTables["CP858"], []rune(internationalCharacterSets[testCase.set])}
+			var got strings.Builder
+			for _, r := range testCase.text {
+				if err := encodeRune(&got, r, "CP858", table, "replace"); err != nil {
+					t.Fatalf("encodeRune returned error: %v", err)
+				}
+			}
+
+			if got.String() != testCase.want {
+				t.Errorf("encodeRune did not return expected bytes: wanted %q, got %q", testCase.want, got.String())
+			}
+		})
+	}
+}
+
+func TestMultiByteTables(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		encoding string
+		want     string
+	}{
+		{"kanji is encoded in Shift-JIS", "日本", "Shift-JIS", "\x93\xFA\x96{"},
+		{"half-width katakana is a single byte in Shift-JIS", "ｶﾅ", "Shift-JIS", "\xB6\xC5"},
+		{"hanzi is encoded in GB18030", "中文", "GB18030", "\xD6\xD0\xCE\xC4"},
+		{"hanzi is encoded in Big5", "中文", "Big5", "\xA4\xA4\xA4\xE5"},
+		{"hangul is encoded in KS C 5601", "한글", "KS C 5601", "\xC7\xD1\xB1\xDB"},
+		{"ASCII is unchanged", "Total: 10", "Big5", "Total: 10"},
+		{"unencodable characters are replaced", "中한", "Shift-JIS", "\x92\x86?"},
+		{"four byte GB18030 sequences are replaced", "中😀", "GB18030", "\xD6\xD0?"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got strings.Builder
+			for _, r := range testCase.text {
+				if err := encodeRune(&got, r, testCase.encoding, multiByteTables[testCase.encoding], "replace"); err != nil {
+					t.Fatalf("encodeRune returned error: %v", err)
+				}
+			}
+
+			if got.String() != testCase.want {
+				t.Errorf("encodeRune did not return expected bytes: wanted %q, got %q", testCase.want, got.String())
+			}
+		})
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"ASCII is one column per character", "Total: 10", 9},
+		{"accented letters are one column", "café", 4},
+		{"kanji are two columns", "ラーメン ¥800", 13},
+		{"half-width katakana are one column", "ﾗｰﾒﾝ", 4},
+		{"hangul is two columns", "김치", 4},
+		{"control characters and combining marks are no columns", "e\u0301\n", 1},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := TextWidth(testCase.text); got != testCase.want {
+				t.Errorf("TextWidth returned %v, wanted %v", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package escpos
+
+type CutConfig struct {
+	mode string
+	feed bool
+}
+
+// DefaultCutConfig creates a CutConfig containing sensible default
+// values for cutting, which feed the paper to the cutter so the last
+// printed line is not cut through.
+func DefaultCutConfig() CutConfig {
+	return CutConfig{
+		mode: "full",
+		feed: true,
+	}
+}
+
+// Mode sets how the paper is cut. Supported values usually include full
+// and partial, which leaves the paper attached at one point. The
+// default is full.
+func (cfg CutConfig) Mode(mode string) CutConfig {
+	cfg.mode = mode
+	return cfg
+}
+
+// Feed sets whether the paper is fed until the last printed line has
+// passed the cutter before it is cut. Without feeding, the cut is made
+// wherever the paper is, which on most printers is through the last
+// printed line, as the cutter sits above the print head. The default is
+// true.
+func (cfg CutConfig) Feed(enabled bool) CutConfig {
+	cfg.feed = enabled
+	return cfg
+}
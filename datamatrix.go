@@ -0,0 +1,62 @@
+package escpos
+
+type DataMatrixConfig struct {
+	shape         string
+	columns       uint8
+	rows          uint8
+	moduleSize    uint8
+	gs1           bool
+	justification string
+}
+
+// DefaultDataMatrixConfig creates a DataMatrixConfig containing
+// sensible default values for DataMatrix (ECC200) printing.
+func DefaultDataMatrixConfig() DataMatrixConfig {
+	return DataMatrixConfig{
+		shape:         "square",
+		columns:       0,
+		rows:          0,
+		moduleSize:    3,
+		gs1:           false,
+		justification: "center",
+	}
+}
+
+// Shape sets the symbol shape, which is usually square or rectangle.
+// The default is square.
+func (cfg DataMatrixConfig) Shape(shape string) DataMatrixConfig {
+	cfg.shape = shape
+	return cfg
+}
+
+// Size sets the number of module columns and rows of the symbol, which
+// must be a size ECC200 defines for the shape, such as 16x16 or 36x12
+// (columns x rows). The default of 0x0 lets the printer choose the
+// smallest size that fits the data.
+func (cfg DataMatrixConfig) Size(columns uint8, rows uint8) DataMatrixConfig {
+	cfg.columns = columns
+	cfg.rows = rows
+	return cfg
+}
+
+// ModuleSize sets the width and height in dots of a module. Most
+// printers support 2 to 16. The default is 3.
+func (cfg DataMatrixConfig) ModuleSize(size uint8) DataMatrixConfig {
+	cfg.moduleSize = size
+	return cfg
+}
+
+// Gs1 sets whether the symbol is a GS1 DataMatrix, which starts with
+// FNC1. Data is then given in bracketed Application Identifier syntax,
+// such as (01)09501101530003(17)250101(10)ABC123. The default is false.
+func (cfg DataMatrixConfig) Gs1(enabled bool) DataMatrixConfig {
+	cfg.gs1 = enabled
+	return cfg
+}
+
+// Justify sets the DataMatrix symbol justification. The default is
+// center.
+func (cfg DataMatrixConfig) Justify(justification string) DataMatrixConfig {
+	cfg.justification = justification
+	return cfg
+}
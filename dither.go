@@ -0,0 +1,211 @@
+package escpos
+
+import (
+	"image"
+	"math"
+)
+
+// Ditherer converts a greyscale image into a 1-bit Bitmap.
+type Ditherer interface {
+	Dither(*image.Gray) *Bitmap
+}
+
+// Threshold prints every pixel darker than Level. This suits logos and
+// line art, which have few shades of grey.
+type Threshold struct {
+	Level uint8
+}
+
+func (threshold Threshold) Dither(gray *image.Gray) *Bitmap {
+	bounds := gray.Bounds()
+	bitmap := NewBitmap(bounds.Dx(), bounds.Dy())
+
+	for y := 0; y < bitmap.Height; y++ {
+		for x := 0; x < bitmap.Width; x++ {
+			bitmap.SetBlack(x, y, gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y < threshold.Level)
+		}
+	}
+
+	return bitmap
+}
+
+// FloydSteinberg dithers by diffusing all of each pixel's quantisation
+// error onto its unprocessed neighbours, which keeps the most detail in
+// photographs.
+type FloydSteinberg struct{}
+
+func (FloydSteinberg) Dither(gray *image.Gray) *Bitmap {
+	return diffuseError(gray, 16, []diffusion{
+		{1, 0, 7},
+		{-1, 1, 3}, {0, 1, 5}, {1, 1, 1},
+	})
+}
+
+// Atkinson dithers by diffusing three quarters of each pixel's
+// quantisation error, giving higher contrast than FloydSteinberg at the
+// cost of detail in highlights and shadows.
+type Atkinson struct{}
+
+func (Atkinson) Dither(gray *image.Gray) *Bitmap {
+	return diffuseError(gray, 8, []diffusion{
+		{1, 0, 1}, {2, 0, 1},
+		{-1, 1, 1}, {0, 1, 1}, {1, 1, 1},
+		{0, 2, 1},
+	})
+}
+
+// Bayer dithers using an ordered Bayer threshold matrix of the given
+// Size, which must be a power of two from 2 to 16. Ordered dithering
+// gives a regular cross-hatched pattern which prints cleanly on thermal
+// paper.
+type Bayer struct {
+	Size int
+}
+
+func (bayer Bayer) Dither(gray *image.Gray) *Bitmap {
+	size := bayer.Size
+	if size < 2 || size > 16 || size&(size-1) != 0 {
+		size = 4
+	}
+	matrix := bayerMatrix(size)
+
+	bounds := gray.Bounds()
+	bitmap := NewBitmap(bounds.Dx(), bounds.Dy())
+
+	for y := 0; y < bitmap.Height; y++ {
+		for x := 0; x < bitmap.Width; x++ {
+			// Thresholds are spread evenly through the range, so a
+			// matrix of n cells prints n+1 distinct shades.
+			level := (2*matrix[y%size][x%size] + 1) * 255 / (2 * size * size)
+			bitmap.SetBlack(x, y, int(gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y) <= level)
+		}
+	}
+
+	return bitmap
+}
+
+// bayerMatrix builds the Bayer index matrix of the given size by
+// recursively tiling the matrix of half the size.
+func bayerMatrix(size int) [][]int {
+	if size == 1 {
+		return [][]int{{0}}
+	}
+
+	half := bayerMatrix(size / 2)
+	matrix := make([][]int, size)
+	for y := range matrix {
+		matrix[y] = make([]int, size)
+		for x := range matrix[y] {
+			value := 4 * half[y%(size/2)][x%(size/2)]
+			switch {
+			case x < size/2 && y < size/2:
+			case x >= size/2 && y >= size/2:
+				value += 1
+			case x >= size/2:
+				value += 2
+			default:
+				value += 3
+			}
+			matrix[y][x] = value
+		}
+	}
+
+	return matrix
+}
+
+// diffusion is the share of quantisation error passed to the pixel at
+// an offset from the current pixel.
+type diffusion struct {
+	dx     int
+	dy     int
+	weight int
+}
+
+// diffuseError dithers the image by thresholding each pixel at mid-grey
+// and passing weight/divisor of the error to each neighbour.
+func diffuseError(gray *image.Gray, divisor int, kernel []diffusion) *Bitmap {
+	bounds := gray.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	bitmap := NewBitmap(width, height)
+
+	values := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			values[y*width+x] = int(gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y)
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := values[y*width+x]
+			black := value < 128
+			bitmap.SetBlack(x, y, black)
+
+			quantisationError := value - 255
+			if black {
+				quantisationError = value
+			}
+
+			for _, d := range kernel {
+				nx, ny := x+d.dx, y+d.dy
+				if nx < 0 || nx >= width || ny >= height {
+					continue
+				}
+				values[ny*width+nx] += quantisationError * d.weight / divisor
+			}
+		}
+	}
+
+	return bitmap
+}
+
+// toneCurve returns a lookup table applying the gamma then contrast
+// adjustment to a grey level.
+func toneCurve(gamma float64, contrast float64) [256]uint8 {
+	var curve [256]uint8
+
+	for i := range curve {
+		value := 255 * math.Pow(float64(i)/255, 1/gamma)
+		value = (value-127.5)*contrast + 127.5
+		curve[i] = uint8(math.Round(min(max(value, 0), 255)))
+	}
+
+	return curve
+}
+
+// ConvertImage converts the image into the Bitmap that WriteImage would
+// print, using the width, tone and Ditherer options of the given
+// ImageConfig. This can be used to preview the 1-bit result, as Bitmap
+// implements image.Image. Unlike WriteImage, the image is not limited
+// to the printable width of any profile.
+func ConvertImage(img image.Image, cfg ImageConfig) (*Bitmap, error) {
+	if img.Bounds().Empty() {
+		return nil, invalidConfig("image is empty: %v", img.Bounds())
+	}
+	if cfg.width < 0 {
+		return nil, invalidConfig("invalid width option in ImageConfig: %v", cfg.width)
+	}
+	if cfg.gamma <= 0 || math.IsNaN(cfg.gamma) || math.IsInf(cfg.gamma, 0) {
+		return nil, invalidConfig("invalid gamma option in ImageConfig: %v", cfg.gamma)
+	}
+	if cfg.contrast < 0 || math.IsNaN(cfg.contrast) || math.IsInf(cfg.contrast, 0) {
+		return nil, invalidConfig("invalid contrast option in ImageConfig: %v", cfg.contrast)
+	}
+
+	width, height := scaledSize(img.Bounds(), cfg.width)
+	gray := resizeGray(toGray(img), width, height)
+
+	if cfg.gamma != 1 || cfg.contrast != 1 {
+		curve := toneCurve(cfg.gamma, cfg.contrast)
+		for i, value := range gray.Pix {
+			gray.Pix[i] = curve[value]
+		}
+	}
+
+	ditherer := cfg.ditherer
+	if ditherer == nil {
+		ditherer = Threshold{Level: 128}
+	}
+
+	return ditherer.Dither(gray), nil
+}
@@ -0,0 +1,99 @@
+package escpos
+
+import (
+	"image"
+	"testing"
+)
+
+func uniformGray(width int, height int, level uint8) *image.Gray {
+	gray := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range gray.Pix {
+		gray.Pix[i] = level
+	}
+	return gray
+}
+
+func countBlack(bitmap *Bitmap) int {
+	count := 0
+	for y := 0; y < bitmap.Height; y++ {
+		for x := 0; x < bitmap.Width; x++ {
+			if bitmap.Black(x, y) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestDitherers(t *testing.T) {
+	cases := []struct {
+		name      string
+		ditherer  Ditherer
+		level     uint8
+		wantBlack int
+	}{
+		{"threshold prints pixels darker than level", Threshold{Level: 100}, 99, 64},
+		{"threshold leaves pixels at level white", Threshold{Level: 100}, 100, 0},
+		{"floyd-steinberg prints half of mid-grey", FloydSteinberg{}, 128, 32},
+		{"atkinson prints all of black", Atkinson{}, 0, 64},
+		{"atkinson prints none of white", Atkinson{}, 255, 0},
+		{"bayer prints half of mid-grey", Bayer{Size: 4}, 127, 32},
+		{"bayer prints a quarter of light grey", Bayer{Size: 2}, 191, 16},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := countBlack(testCase.ditherer.Dither(uniformGray(8, 8, testCase.level)))
+
+			if got != testCase.wantBlack {
+				t.Errorf("Dither did not print expected dots: wanted %v, got %v", testCase.wantBlack, got)
+			}
+		})
+	}
+}
+
+func TestConvertImage(t *testing.T) {
+	t.Run("image is scaled to configured width", func(t *testing.T) {
+		bitmap, err := ConvertImage(uniformGray(40, 20, 0), DefaultImageConfig().Width(10))
+
+		if err != nil {
+			t.Fatalf("ConvertImage returned error: %v", err)
+		}
+
+		if bitmap.Width != 10 || bitmap.Height != 5 || countBlack(bitmap) != 50 {
+			t.Errorf("ConvertImage did not scale image, got %vx%v with %v black dots", bitmap.Width, bitmap.Height, countBlack(bitmap))
+		}
+	})
+
+	t.Run("gamma lightens mid-tones before thresholding", func(t *testing.T) {
+		bitmap, err := ConvertImage(uniformGray(4, 4, 100), DefaultImageConfig().Gamma(2))
+
+		if err != nil {
+			t.Fatalf("ConvertImage returned error: %v", err)
+		}
+
+		if countBlack(bitmap) != 0 {
+			t.Errorf("ConvertImage did not apply gamma, got %v black dots", countBlack(bitmap))
+		}
+	})
+
+	t.Run("contrast darkens dark tones before thresholding", func(t *testing.T) {
+		bitmap, err := ConvertImage(uniformGray(4, 4, 125), DefaultImageConfig().Dither(Threshold{Level: 120}).Contrast(4))
+
+		if err != nil {
+			t.Fatalf("ConvertImage returned error: %v", err)
+		}
+
+		if countBlack(bitmap) != 16 {
+			t.Errorf("ConvertImage did not apply contrast, got %v black dots", countBlack(bitmap))
+		}
+	})
+
+	t.Run("invalid gamma returns error", func(t *testing.T) {
+		_, err := ConvertImage(uniformGray(4, 4, 100), DefaultImageConfig().Gamma(0))
+
+		if err == nil || err.Error() != "invalid gamma option in ImageConfig: 0" {
+			t.Errorf("ConvertImage did not return expected error, got %v", err)
+		}
+	})
+}
@@ -1,25 +1,89 @@
 package escpos
 
 import (
-	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 const (
-	qrCodeSymbol byte = 49
+	pdf417Symbol     byte = 48
+	qrCodeSymbol     byte = 49
+	maxiCodeSymbol   byte = 50
+	aztecSymbol      byte = 53
+	dataMatrixSymbol byte = 54
 )
 
+// epsonCodeTables holds the ESC t page of each character code table
+// supported by Epson printers, most preferred first.
+var epsonCodeTables = []struct {
+	name string
+	page byte
+}{
+	{"CP437", 0}, {"CP858", 19}, {"CP850", 2}, {"WPC1252", 16}, {"CP852", 18}, {"CP866", 17},
+	{"CP860", 3}, {"CP863", 4}, {"CP865", 5}, {"Katakana", 1}, {"ISO8859-2", 39}, {"ISO8859-15", 40},
+	{"WPC1250", 45}, {"WPC1251", 46}, {"WPC1253", 47}, {"WPC1254", 48}, {"WPC1255", 49},
+	{"WPC1256", 50}, {"WPC1257", 51}, {"WPC1258", 52},
+}
+
+// epsonInternationalCharacterSets holds the ESC R value of each
+// international character set supported by Epson printers. The India
+// sets from 66 up are left out: only India models of the printers have
+// them, and they select Indian script fonts rather than replacing the
+// characters in internationalPositions.
+var epsonInternationalCharacterSets = map[string]byte{
+	"USA": 0, "France": 1, "Germany": 2, "UK": 3, "Denmark I": 4, "Sweden": 5, "Italy": 6,
+	"Spain I": 7, "Japan": 8, "Norway": 9, "Denmark II": 10, "Spain II": 11, "Latin America": 12,
+	"Korea": 13, "Slovenia/Croatia": 14, "China": 15, "Vietnam": 16,
+}
+
+// epsonFontPriorities holds the FS ( C font type of each font which can
+// be given priority for UTF-8 text.
+var epsonFontPriorities = map[string]byte{
+	"ANK": 0, "Japanese": 11, "Simplified Chinese": 20, "Traditional Chinese": 30, "Korean": 40,
+}
+
 // EpsonTMT20III implements the ESC/POS commands specific to the Epson
 // TM-T20III printer.
 type EpsonTMT20III struct {
+	// Encoding is the multi-byte character encoding of the printer's
+	// firmware: Shift-JIS for Japanese models, GB18030 for Simplified
+	// Chinese, Big5 for Traditional Chinese and KS C 5601 for Korean.
+	// It is empty for models without multi-byte characters.
+	Encoding string
+
+	// UTF8 reports whether the printer's firmware accepts UTF-8 text.
+	UTF8 bool
 }
 
 func (EpsonTMT20III) InitCommand() (string, error) {
 	return "\x1B@", nil
 }
 
-func (EpsonTMT20III) CutCommand() (string, error) {
-	return "\x1DVA0", nil
+func (EpsonTMT20III) CutCommand(cfg *CutConfig) (string, error) {
+	// Feeding to the cutter position first is function B of the command,
+	// here with no feed beyond the cutter position.
+	switch {
+	case cfg.mode == "full" && cfg.feed:
+		return "\x1DVA\x00", nil
+	case cfg.mode == "partial" && cfg.feed:
+		return "\x1DVB\x00", nil
+	case cfg.mode == "full":
+		return "\x1DV0", nil
+	case cfg.mode == "partial":
+		return "\x1DV1", nil
+	default:
+		return "", invalidConfig("invalid mode option in CutConfig: %v", cfg.mode)
+	}
+}
+
+func (EpsonTMT20III) CutterDistance() int {
+	// The cutter is about 15 mm above the print head.
+	return 120
+}
+
+func (EpsonTMT20III) FeedsToCutter() bool {
+	return true
 }
 
 func (EpsonTMT20III) EndCommand() (string, error) {
@@ -37,7 +101,7 @@ func (EpsonTMT20III) FontCommand(fmtCfg *FormatConfig) (string, error) {
 	case "D":
 		return "\x1BM3", nil
 	default:
-		return "", errors.New("invalid font option in FormatConfig")
+		return "", invalidConfig("invalid font option in FormatConfig")
 	}
 }
 
@@ -50,7 +114,7 @@ func (EpsonTMT20III) JustificationCommand(fmtCfg *FormatConfig) (string, error)
 	case "right":
 		return "\x1Ba2", nil
 	default:
-		return "", errors.New("invalid justification option in FormatConfig")
+		return "", invalidConfig("invalid justification option in FormatConfig")
 	}
 }
 
@@ -71,14 +135,13 @@ func (EpsonTMT20III) UnderlineCommand(fmtCfg *FormatConfig) (string, error) {
 	case "2-dots":
 		return "\x1B-2", nil
 	default:
-		return "", errors.New("invalid underline option in FormatConfig")
+		return "", invalidConfig("invalid underline option in FormatConfig")
 	}
 }
 
 func (EpsonTMT20III) CharSizeCommand(fmtCfg *FormatConfig) (string, error) {
 	if fmtCfg.charWidth < 1 || fmtCfg.charHeight < 1 || fmtCfg.charWidth > 8 || fmtCfg.charHeight > 8 {
-		message := fmt.Sprintf("invalid charsize options in FormatConfig: width %v, height %v\n", fmtCfg.charWidth, fmtCfg.charHeight)
-		return "", errors.New(message)
+		return "", invalidConfig("invalid charsize options in FormatConfig: width %v, height %v", fmtCfg.charWidth, fmtCfg.charHeight)
 	}
 
 	sizeByte := ((fmtCfg.charWidth - 1) << 4) | (fmtCfg.charHeight - 1)
@@ -86,37 +149,77 @@ func (EpsonTMT20III) CharSizeCommand(fmtCfg *FormatConfig) (string, error) {
 	return fmt.Sprintf("\x1D!%c", sizeByte), nil
 }
 
+func (EpsonTMT20III) ReverseCommand(fmtCfg *FormatConfig) (string, error) {
+	if fmtCfg.reverse {
+		return "\x1DB1", nil
+	} else {
+		return "\x1DB0", nil
+	}
+}
+
+func (EpsonTMT20III) DoubleStrikeCommand(fmtCfg *FormatConfig) (string, error) {
+	if fmtCfg.doubleStrike {
+		return "\x1BG1", nil
+	} else {
+		return "\x1BG0", nil
+	}
+}
+
+func (EpsonTMT20III) UpsideDownCommand(fmtCfg *FormatConfig) (string, error) {
+	if fmtCfg.upsideDown {
+		return "\x1B{1", nil
+	} else {
+		return "\x1B{0", nil
+	}
+}
+
+func (EpsonTMT20III) RotationCommand(fmtCfg *FormatConfig) (string, error) {
+	if fmtCfg.rotate {
+		return "\x1BV1", nil
+	} else {
+		return "\x1BV0", nil
+	}
+}
+
+func (EpsonTMT20III) SmoothingCommand(fmtCfg *FormatConfig) (string, error) {
+	if fmtCfg.smoothing {
+		return "\x1Db1", nil
+	} else {
+		return "\x1Db0", nil
+	}
+}
+
 func (EpsonTMT20III) SelectQrCodeModelCommand(cfg *QrCodeConfig) (string, error) {
 	switch cfg.model {
 	case "1":
-		return string([]byte{'\x1D', '(', 'k', 4, 0, qrCodeSymbol, 65, 49, 0}), nil
+		return symbolCommand(qrCodeSymbol, 65, 49, 0), nil
 	case "2":
-		return string([]byte{'\x1D', '(', 'k', 4, 0, qrCodeSymbol, 65, 50, 0}), nil
+		return symbolCommand(qrCodeSymbol, 65, 50, 0), nil
 	default:
-		return "", errors.New(fmt.Sprintf("invalid model in QrCodeConfig: %v\n", cfg.model))
+		return "", invalidConfig("invalid model in QrCodeConfig: %v", cfg.model)
 	}
 }
 
 func (EpsonTMT20III) SetQrCodeSizeCommand(cfg *QrCodeConfig) (string, error) {
 	if cfg.size < 1 || cfg.size > 16 {
-		return "", errors.New(fmt.Sprintf("invalid size option in QrCodeConfig: %v\n", cfg.size))
+		return "", invalidConfig("invalid size option in QrCodeConfig: %v", cfg.size)
 	}
 
-	return string([]byte{'\x1D', '(', 'k', 3, 0, qrCodeSymbol, 67, byte(cfg.size)}), nil
+	return symbolCommand(qrCodeSymbol, 67, byte(cfg.size)), nil
 }
 
 func (EpsonTMT20III) SelectQrCodeErrorCorrectionLevelCommand(cfg *QrCodeConfig) (string, error) {
 	switch cfg.errorCorrection {
 	case "L":
-		return string([]byte{'\x1D', '(', 'k', 3, 0, qrCodeSymbol, 69, 48}), nil
+		return symbolCommand(qrCodeSymbol, 69, 48), nil
 	case "M":
-		return string([]byte{'\x1D', '(', 'k', 3, 0, qrCodeSymbol, 69, 49}), nil
+		return symbolCommand(qrCodeSymbol, 69, 49), nil
 	case "Q":
-		return string([]byte{'\x1D', '(', 'k', 3, 0, qrCodeSymbol, 69, 50}), nil
+		return symbolCommand(qrCodeSymbol, 69, 50), nil
 	case "H":
-		return string([]byte{'\x1D', '(', 'k', 3, 0, qrCodeSymbol, 69, 51}), nil
+		return symbolCommand(qrCodeSymbol, 69, 51), nil
 	default:
-		return "", errors.New(fmt.Sprintf("invalid error correction level option in QrCodeConfig: %v\n", cfg.errorCorrection))
+		return "", invalidConfig("invalid error correction level option in QrCodeConfig: %v", cfg.errorCorrection)
 	}
 }
 
@@ -124,15 +227,628 @@ func (EpsonTMT20III) StoreQrCodeDataCommand(data string) (string, error) {
 	dataLength := len(data)
 
 	if dataLength > 7086 {
-		return "", errors.New(fmt.Sprintf("maximum data length exceeded: %v > 7086 (max)\n", dataLength))
+		return "", invalidConfig("maximum data length exceeded: %v > 7086 (max)", dataLength)
 	}
 
-	var pH byte = 0
-	bytesAfterPh := byte(dataLength + 3)
-
-	return string(append([]byte{'\x1D', '(', 'k', bytesAfterPh, pH, qrCodeSymbol, 80, 48}, data...)), nil
+	return symbolCommand(qrCodeSymbol, 80, append([]byte{48}, data...)...), nil
 }
 
 func (EpsonTMT20III) PrintQrCodeDataCommand() (string, error) {
-	return string([]byte{'\x1D', '(', 'k', 3, 0, qrCodeSymbol, 81, 48}), nil
+	return symbolCommand(qrCodeSymbol, 81, 48), nil
+}
+
+func (EpsonTMT20III) CodeTables() []string {
+	names := make([]string, len(epsonCodeTables))
+	for i, table := range epsonCodeTables {
+		names[i] = table.name
+	}
+	return names
+}
+
+func (EpsonTMT20III) SelectCodeTableCommand(table string) (string, error) {
+	for _, candidate := range epsonCodeTables {
+		if candidate.name == table {
+			return string([]byte{'\x1B', 't', candidate.page}), nil
+		}
+	}
+	return "", invalidConfig("invalid code table: %v", table)
+}
+
+func (profile EpsonTMT20III) MultiByteEncodings() []string {
+	if profile.Encoding == "" {
+		return nil
+	}
+	return []string{profile.Encoding}
+}
+
+func (profile EpsonTMT20III) EnableMultiByteCommand(encoding string) (string, error) {
+	switch {
+	case encoding != profile.Encoding || encoding == "":
+		return "", invalidConfig("invalid multi-byte encoding: %v", encoding)
+	case encoding == "Shift-JIS":
+		return "\x1CC\x01\x1C&", nil
+	default:
+		return "\x1C&", nil
+	}
+}
+
+func (profile EpsonTMT20III) DisableMultiByteCommand(encoding string) (string, error) {
+	switch {
+	case encoding != profile.Encoding || encoding == "":
+		return "", invalidConfig("invalid multi-byte encoding: %v", encoding)
+	case encoding == "Shift-JIS":
+		return "\x1C.\x1CC\x00", nil
+	default:
+		return "\x1C.", nil
+	}
+}
+
+func (profile EpsonTMT20III) SelectUTF8Command(priority string) (string, error) {
+	if !profile.UTF8 {
+		return "", unsupported("UTF-8")
+	}
+
+	command := "\x1C(C\x02\x000\x02"
+	if priority == "" {
+		return command, nil
+	}
+
+	font, ok := epsonFontPriorities[priority]
+	if !ok {
+		return "", invalidConfig("invalid font priority: %v", priority)
+	}
+	return command + string([]byte{'\x1C', '(', 'C', 3, 0, '<', 0, font}), nil
+}
+
+func (EpsonTMT20III) SelectInternationalCharacterSetCommand(set string) (string, error) {
+	n, ok := epsonInternationalCharacterSets[set]
+	if !ok {
+		return "", invalidConfig("invalid international character set: %v", set)
+	}
+	return string([]byte{'\x1B', 'R', n}), nil
+}
+
+func (EpsonTMT20III) PrintableWidth() int {
+	return 576
+}
+
+func (EpsonTMT20III) DotsPerInch() int {
+	return 203
+}
+
+func (EpsonTMT20III) RasterImageCommand(bitmap *Bitmap) (string, error) {
+	bytesPerRow := bitmap.Stride()
+	if bitmap.Width < 1 || bitmap.Height < 1 || bytesPerRow > 0xFFFF || bitmap.Height > 2303 {
+		return "", invalidConfig("invalid raster image size: width %v, height %v", bitmap.Width, bitmap.Height)
+	}
+
+	header := []byte{'\x1D', 'v', '0', 0, byte(bytesPerRow), byte(bytesPerRow >> 8), byte(bitmap.Height), byte(bitmap.Height >> 8)}
+
+	return string(append(header, bitmap.Pix...)), nil
+}
+
+func (EpsonTMT20III) MaxRasterBandHeight() int {
+	return 2303
+}
+
+func (EpsonTMT20III) EnterPageModeCommand() (string, error) {
+	return "\x1BL", nil
+}
+
+func (EpsonTMT20III) SetPageAreaCommand(x int, y int, width int, height int) (string, error) {
+	for _, value := range []int{x, y, width, height} {
+		if value < 0 || value > 0xFFFF {
+			return "", invalidConfig("invalid page area: x %v, y %v, width %v, height %v", x, y, width, height)
+		}
+	}
+	if width < 1 || height < 1 {
+		return "", invalidConfig("invalid page area: x %v, y %v, width %v, height %v", x, y, width, height)
+	}
+
+	return string([]byte{'\x1B', 'W', byte(x), byte(x >> 8), byte(y), byte(y >> 8),
+		byte(width), byte(width >> 8), byte(height), byte(height >> 8)}), nil
+}
+
+func (EpsonTMT20III) SetPagePositionCommand(x int, y int) (string, error) {
+	if x < 0 || x > 0xFFFF || y < 0 || y > 0xFFFF {
+		return "", invalidConfig("invalid page position: x %v, y %v", x, y)
+	}
+
+	return string([]byte{'\x1B', '$', byte(x), byte(x >> 8), '\x1D', '$', byte(y), byte(y >> 8)}), nil
+}
+
+func (EpsonTMT20III) PrintPageCommand() (string, error) {
+	return "\x0C", nil
+}
+
+func (EpsonTMT20III) SetBarcodeWidthCommand(cfg *BarcodeConfig) (string, error) {
+	if cfg.width < 2 || cfg.width > 6 {
+		return "", invalidConfig("invalid width option in BarcodeConfig: %v", cfg.width)
+	}
+
+	return string([]byte{'\x1D', 'w', cfg.width}), nil
+}
+
+func (EpsonTMT20III) SetBarcodeHeightCommand(cfg *BarcodeConfig) (string, error) {
+	if cfg.height < 1 {
+		return "", invalidConfig("invalid height option in BarcodeConfig: %v", cfg.height)
+	}
+
+	return string([]byte{'\x1D', 'h', cfg.height}), nil
+}
+
+func (EpsonTMT20III) SelectHriPositionCommand(cfg *BarcodeConfig) (string, error) {
+	switch cfg.hriPosition {
+	case "none":
+		return "\x1DH0", nil
+	case "above":
+		return "\x1DH1", nil
+	case "below":
+		return "\x1DH2", nil
+	case "both":
+		return "\x1DH3", nil
+	default:
+		return "", invalidConfig("invalid HRI position option in BarcodeConfig: %v", cfg.hriPosition)
+	}
+}
+
+func (EpsonTMT20III) SelectHriFontCommand(cfg *BarcodeConfig) (string, error) {
+	switch cfg.hriFont {
+	case "A":
+		return "\x1Df0", nil
+	case "B":
+		return "\x1Df1", nil
+	default:
+		return "", invalidConfig("invalid HRI font option in BarcodeConfig: %v", cfg.hriFont)
+	}
+}
+
+func (EpsonTMT20III) PrintBarcodeCommand(data string, cfg *BarcodeConfig) (string, error) {
+	var m byte
+	switch cfg.symbology {
+	case "UPC-A":
+		m = 65
+	case "UPC-E":
+		m = 66
+	case "EAN-13":
+		m = 67
+	case "EAN-8":
+		m = 68
+	case "CODE39":
+		m = 69
+	case "ITF":
+		m = 70
+	case "CODABAR":
+		m = 71
+	case "CODE93":
+		m = 72
+	case "CODE128", "GS1-128":
+		m = 73
+		chars, err := optimiseCode128(data, cfg.symbology)
+		if err != nil {
+			return "", err
+		}
+		data = epsonCode128Data(chars)
+	default:
+		return "", invalidConfig("invalid symbology option in BarcodeConfig: %v", cfg.symbology)
+	}
+
+	if len(data) > 255 {
+		return "", invalidConfig("maximum barcode data length exceeded: %v > 255 (max)", len(data))
+	}
+
+	return string(append([]byte{'\x1D', 'k', m, byte(len(data))}, data...)), nil
+}
+
+// epsonCode128Data formats the CODE128 symbol characters as the
+// TM-T20III expects, with code sets selected by {A, {B and {C, FNC1 as
+// {1, a literal brace as {{ and code set C data as bytes 0 to 99.
+func epsonCode128Data(chars []code128Char) string {
+	var builder strings.Builder
+	for _, char := range chars {
+		switch {
+		case char.isSwitch:
+			builder.WriteString("{" + string(char.codeSet))
+		case char.fnc1:
+			builder.WriteString("{1")
+		case char.data == '{' && char.codeSet != 'C':
+			builder.WriteString("{{")
+		default:
+			builder.WriteByte(char.data)
+		}
+	}
+	return builder.String()
+}
+
+// symbolCommand returns a GS ( k command for the given symbol and
+// function, encoding the parameter length in pL and pH.
+func symbolCommand(cn byte, fn byte, params ...byte) string {
+	length := len(params) + 2
+	return string(append([]byte{'\x1D', '(', 'k', byte(length), byte(length >> 8), cn, fn}, params...))
+}
+
+func (EpsonTMT20III) SetPdf417ColumnsCommand(cfg *Pdf417Config) (string, error) {
+	if cfg.columns > 30 {
+		return "", invalidConfig("invalid columns option in Pdf417Config: %v", cfg.columns)
+	}
+
+	return symbolCommand(pdf417Symbol, 65, cfg.columns), nil
+}
+
+func (EpsonTMT20III) SetPdf417RowsCommand(cfg *Pdf417Config) (string, error) {
+	if cfg.rows != 0 && (cfg.rows < 3 || cfg.rows > 90) {
+		return "", invalidConfig("invalid rows option in Pdf417Config: %v", cfg.rows)
+	}
+
+	return symbolCommand(pdf417Symbol, 66, cfg.rows), nil
+}
+
+func (EpsonTMT20III) SetPdf417ModuleWidthCommand(cfg *Pdf417Config) (string, error) {
+	if cfg.moduleWidth < 2 || cfg.moduleWidth > 8 {
+		return "", invalidConfig("invalid module width option in Pdf417Config: %v", cfg.moduleWidth)
+	}
+
+	return symbolCommand(pdf417Symbol, 67, cfg.moduleWidth), nil
+}
+
+func (EpsonTMT20III) SetPdf417RowHeightCommand(cfg *Pdf417Config) (string, error) {
+	if cfg.rowHeight < 2 || cfg.rowHeight > 8 {
+		return "", invalidConfig("invalid row height option in Pdf417Config: %v", cfg.rowHeight)
+	}
+
+	return symbolCommand(pdf417Symbol, 68, cfg.rowHeight), nil
+}
+
+func (EpsonTMT20III) SelectPdf417ErrorCorrectionLevelCommand(cfg *Pdf417Config) (string, error) {
+	switch {
+	case cfg.errorCorrection == "level" && cfg.errorLevel <= 8:
+		return symbolCommand(pdf417Symbol, 69, 48, 48+cfg.errorLevel), nil
+	case cfg.errorCorrection == "ratio" && cfg.errorLevel >= 1 && cfg.errorLevel <= 40:
+		return symbolCommand(pdf417Symbol, 69, 49, cfg.errorLevel), nil
+	default:
+		return "", invalidConfig("invalid error correction %v option in Pdf417Config: %v", cfg.errorCorrection, cfg.errorLevel)
+	}
+}
+
+func (EpsonTMT20III) SelectPdf417OptionsCommand(cfg *Pdf417Config) (string, error) {
+	if cfg.truncated {
+		return symbolCommand(pdf417Symbol, 70, 1), nil
+	}
+
+	return symbolCommand(pdf417Symbol, 70, 0), nil
+}
+
+func (EpsonTMT20III) StorePdf417DataCommand(data string) (string, error) {
+	if len(data) < 1 || len(data) > 65532 {
+		return "", invalidConfig("invalid PDF417 data length: %v", len(data))
+	}
+
+	return symbolCommand(pdf417Symbol, 80, append([]byte{48}, data...)...), nil
+}
+
+func (EpsonTMT20III) PrintPdf417DataCommand() (string, error) {
+	return symbolCommand(pdf417Symbol, 81, 48), nil
+}
+
+func (EpsonTMT20III) SelectMaxiCodeModeCommand(cfg *MaxiCodeConfig) (string, error) {
+	if cfg.mode < 2 || cfg.mode > 6 {
+		return "", invalidConfig("invalid mode option in MaxiCodeConfig: %v", cfg.mode)
+	}
+
+	return symbolCommand(maxiCodeSymbol, 65, '0'+cfg.mode), nil
+}
+
+func (EpsonTMT20III) StoreMaxiCodeDataCommand(data string) (string, error) {
+	if len(data) < 1 || len(data) > 138 {
+		return "", invalidConfig("invalid MaxiCode data length: %v", len(data))
+	}
+
+	return symbolCommand(maxiCodeSymbol, 80, append([]byte{48}, data...)...), nil
+}
+
+func (EpsonTMT20III) PrintMaxiCodeDataCommand() (string, error) {
+	return symbolCommand(maxiCodeSymbol, 81, 48), nil
+}
+
+func (EpsonTMT20III) StatusCommand(kind string) (string, error) {
+	switch kind {
+	case "printer":
+		return "\x10\x04\x01", nil
+	case "offline":
+		return "\x10\x04\x02", nil
+	case "error":
+		return "\x10\x04\x03", nil
+	case "paper":
+		return "\x10\x04\x04", nil
+	default:
+		return "", invalidConfig("invalid status: %v", kind)
+	}
+}
+
+func (EpsonTMT20III) DecodeStatus(kind string, reply byte, status *Status) error {
+	// Bits 1 and 4 of every status are set, and bits 0 and 7 clear.
+	if reply&0x93 != 0x12 {
+		return &StatusError{Reply: reply}
+	}
+
+	switch kind {
+	case "printer":
+		status.DrawerPinHigh = reply&0x04 != 0
+		status.Offline = reply&0x08 != 0
+		status.FeedButtonPressed = reply&0x40 != 0
+	case "offline":
+		status.CoverOpen = reply&0x04 != 0
+		status.PaperFeeding = reply&0x08 != 0
+		status.PaperEnd = reply&0x20 != 0
+		status.Error = reply&0x40 != 0
+	case "error":
+		status.CutterError = reply&0x08 != 0
+		status.UnrecoverableError = reply&0x20 != 0
+		status.AutoRecoverableError = reply&0x40 != 0
+	case "paper":
+		status.PaperNearEnd = reply&0x0C != 0
+		status.PaperEnd = reply&0x60 != 0
+	default:
+		return invalidConfig("invalid status: %v", kind)
+	}
+	return nil
+}
+
+func (EpsonTMT20III) EnableAutomaticStatusBackCommand() (string, error) {
+	return "\x1Da\x0F", nil
+}
+
+func (EpsonTMT20III) DisableAutomaticStatusBackCommand() (string, error) {
+	return "\x1Da\x00", nil
+}
+
+func (EpsonTMT20III) AutomaticStatusBackLength(first byte) int {
+	// The first byte has bit 4 set and bits 0, 1 and 7 clear, unlike
+	// real-time status replies, which also have bit 1 set.
+	if first&0x93 == 0x10 {
+		return 4
+	}
+	return 0
+}
+
+func (EpsonTMT20III) DecodeAutomaticStatusBack(message []byte, status *Status) error {
+	if len(message) != 4 {
+		return &StatusError{}
+	}
+	if message[0]&0x93 != 0x10 {
+		return &StatusError{Reply: message[0]}
+	}
+	for _, b := range message[1:] {
+		if b&0x90 != 0 {
+			return &StatusError{Reply: b}
+		}
+	}
+
+	status.DrawerPinHigh = message[0]&0x04 != 0
+	status.Offline = message[0]&0x08 != 0
+	status.CoverOpen = message[0]&0x20 != 0
+	status.PaperFeeding = message[0]&0x40 != 0
+	status.CutterError = message[1]&0x08 != 0
+	status.UnrecoverableError = message[1]&0x20 != 0
+	status.AutoRecoverableError = message[1]&0x40 != 0
+	status.Error = status.CutterError || status.UnrecoverableError || status.AutoRecoverableError
+	status.PaperNearEnd = message[2]&0x03 != 0
+	status.PaperEnd = message[2]&0x0C != 0
+	return nil
+}
+
+func (EpsonTMT20III) ProcessIDCommand(id string) (string, error) {
+	if len(id) != 4 || strings.Trim(id, "0123456789") != "" {
+		return "", invalidConfig("invalid process ID: %v", id)
+	}
+	return "\x1D(H\x06\x0000" + id, nil
+}
+
+func (EpsonTMT20III) ProcessIDResponseLength(first byte) int {
+	if first == 0x37 {
+		return 7
+	}
+	return 0
+}
+
+func (EpsonTMT20III) DecodeProcessIDResponse(response []byte) (string, error) {
+	// The response is a header, an identifier, the four digits and NUL.
+	if len(response) != 7 {
+		return "", &StatusError{}
+	}
+	if response[0] != 0x37 || response[1] != 0x22 {
+		return "", &StatusError{Reply: response[0]}
+	}
+	if response[6] != 0x00 {
+		return "", &StatusError{Reply: response[6]}
+	}
+	return string(response[2:6]), nil
+}
+
+func (EpsonTMT20III) IdentificationCommand(kind string) (string, error) {
+	switch kind {
+	case "model ID":
+		return "\x1DI\x01", nil
+	case "type ID":
+		return "\x1DI\x02", nil
+	case "firmware version":
+		return "\x1DIA", nil
+	case "manufacturer":
+		return "\x1DIB", nil
+	case "model name":
+		return "\x1DIC", nil
+	case "serial number":
+		return "\x1DID", nil
+	default:
+		return "", invalidConfig("invalid printer information: %v", kind)
+	}
+}
+
+func (EpsonTMT20III) IdentificationBlock(first byte) bool {
+	return first == 0x5F
+}
+
+func (EpsonTMT20III) DecodeIdentification(kind string, reply []byte, info *PrinterInfo) error {
+	switch kind {
+	case "model ID", "type ID":
+		if len(reply) != 1 {
+			return &StatusError{}
+		}
+		// Bits 4 and 7 of the type ID are always clear.
+		if kind == "type ID" && reply[0]&0x90 != 0 {
+			return &StatusError{Reply: reply[0]}
+		}
+		if kind == "model ID" {
+			info.ModelID = reply[0]
+		} else {
+			info.TypeID = reply[0]
+		}
+		return nil
+	}
+
+	// Other information is a header, the text and NUL.
+	if len(reply) < 2 {
+		return &StatusError{}
+	}
+	if reply[0] != 0x5F || reply[len(reply)-1] != 0x00 {
+		return &StatusError{Reply: reply[0]}
+	}
+	text := string(reply[1 : len(reply)-1])
+
+	switch kind {
+	case "firmware version":
+		info.FirmwareVersion = text
+	case "manufacturer":
+		info.Manufacturer = text
+	case "model name":
+		info.ModelName = text
+	case "serial number":
+		info.SerialNumber = text
+	default:
+		return invalidConfig("invalid printer information: %v", kind)
+	}
+	return nil
+}
+
+// epsonDrawerPin returns the parameter selecting the drawer kick-out
+// connector pin.
+func epsonDrawerPin(pin int) (byte, error) {
+	switch pin {
+	case 2:
+		return 0, nil
+	case 5:
+		return 1, nil
+	default:
+		return 0, invalidConfig("invalid drawer kick-out connector pin: %v", pin)
+	}
+}
+
+func (EpsonTMT20III) DrawerPulseCommand(pin int, onTime time.Duration, offTime time.Duration) (string, error) {
+	m, err := epsonDrawerPin(pin)
+	if err != nil {
+		return "", err
+	}
+
+	// Times are set in units of 2 ms.
+	on := onTime.Round(2*time.Millisecond) / (2 * time.Millisecond)
+	off := offTime.Round(2*time.Millisecond) / (2 * time.Millisecond)
+	if on < 1 || on > 255 || off < 1 || off > 255 {
+		return "", invalidConfig("invalid drawer pulse: on %v, off %v", onTime, offTime)
+	}
+	return string([]byte{'\x1B', 'p', m, byte(on), byte(off)}), nil
+}
+
+func (EpsonTMT20III) RealTimeDrawerPulseCommand(pin int, pulse time.Duration) (string, error) {
+	m, err := epsonDrawerPin(pin)
+	if err != nil {
+		return "", err
+	}
+
+	// The pulse is set in units of 100 ms.
+	t := pulse.Round(100*time.Millisecond) / (100 * time.Millisecond)
+	if t < 1 || t > 8 {
+		return "", invalidConfig("invalid drawer pulse: %v", pulse)
+	}
+	return string([]byte{'\x10', '\x14', 1, m, byte(t)}), nil
+}
+
+func (EpsonTMT20III) FeedLinesCommand(lines int) (string, error) {
+	if lines < 0 || lines > 255 {
+		return "", invalidConfig("invalid feed: %v lines", lines)
+	}
+	return string([]byte{'\x1B', 'd', byte(lines)}), nil
+}
+
+func (EpsonTMT20III) FeedDotsCommand(dots int) (string, error) {
+	return epsonFeedUnits(dots, 1)
+}
+
+// epsonFeedUnits returns the commands feeding the paper by the given
+// number of dots, at the given number of vertical motion units per dot.
+// Feeds longer than a single command allows are split.
+func epsonFeedUnits(dots int, unitsPerDot int) (string, error) {
+	if dots < 0 {
+		return "", invalidConfig("invalid feed: %v dots", dots)
+	}
+
+	var command strings.Builder
+	for units := dots * unitsPerDot; units > 0; units -= min(units, 255) {
+		command.Write([]byte{'\x1B', 'J', byte(min(units, 255))})
+	}
+	return command.String(), nil
+}
+
+func (EpsonTMT20III) LineSpacingCommand(dots int) (string, error) {
+	return epsonLineSpacing(dots, 1)
+}
+
+// epsonLineSpacing returns the command setting the line spacing to the
+// given number of dots, at the given number of vertical motion units per
+// dot.
+func epsonLineSpacing(dots int, unitsPerDot int) (string, error) {
+	units := dots * unitsPerDot
+	if dots < 0 || units > 255 {
+		return "", invalidConfig("invalid line spacing: %v dots", dots)
+	}
+	return string([]byte{'\x1B', '3', byte(units)}), nil
+}
+
+func (EpsonTMT20III) DefaultLineSpacingCommand() (string, error) {
+	return "\x1B2", nil
+}
+
+func (EpsonTMT20III) CharSpacingCommand(dots int) (string, error) {
+	if dots < 0 || dots > 255 {
+		return "", invalidConfig("invalid character spacing: %v dots", dots)
+	}
+	return string([]byte{'\x1B', ' ', byte(dots)}), nil
+}
+
+func (EpsonTMT20III) LeftMarginCommand(dots int) (string, error) {
+	if dots < 0 || dots > 0xFFFF {
+		return "", invalidConfig("invalid left margin: %v dots", dots)
+	}
+	return string([]byte{'\x1D', 'L', byte(dots), byte(dots >> 8)}), nil
+}
+
+func (EpsonTMT20III) PrintAreaWidthCommand(dots int) (string, error) {
+	if dots < 1 || dots > 0xFFFF {
+		return "", invalidConfig("invalid print area width: %v dots", dots)
+	}
+	return string([]byte{'\x1D', 'W', byte(dots), byte(dots >> 8)}), nil
+}
+
+func (EpsonTMT20III) AbsolutePositionCommand(dots int) (string, error) {
+	if dots < 0 || dots > 0xFFFF {
+		return "", invalidConfig("invalid print position: %v dots", dots)
+	}
+	return string([]byte{'\x1B', '$', byte(dots), byte(dots >> 8)}), nil
+}
+
+func (EpsonTMT20III) RelativePositionCommand(dots int) (string, error) {
+	// Moves to the left are given in two's complement.
+	if dots < -0x8000 || dots > 0x7FFF {
+		return "", invalidConfig("invalid print position: %v dots", dots)
+	}
+	return string([]byte{'\x1B', '\\', byte(dots), byte(dots >> 8)}), nil
 }
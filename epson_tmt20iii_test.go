@@ -2,9 +2,11 @@ package escpos
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestEpsonTMT20III_SimpleCommands(t *testing.T) {
@@ -16,7 +18,6 @@ func TestEpsonTMT20III_SimpleCommands(t *testing.T) {
 		want        []byte
 	}{
 		{"init command returns correct value", profile.InitCommand, []byte{'\x1B', '@'}},
-		{"cut command returns correct value", profile.CutCommand, []byte{'\x1D', 'V', 'A', '0'}},
 		{"end command returns correct value", profile.EndCommand, []byte{'\xFA'}},
 		{"print qr code command returns correct value", profile.PrintQrCodeDataCommand, []byte{'\x1D', '(', 'k', 3, 0, 49, 81, 48}},
 	}
@@ -38,6 +39,82 @@ func TestEpsonTMT20III_SimpleCommands(t *testing.T) {
 	}
 }
 
+func TestEpsonTMT20III_CutCommand(t *testing.T) {
+	var profile Profile = EpsonTMT20III{}
+
+	cases := []struct {
+		name string
+		cfg  CutConfig
+		want []byte
+	}{
+		{"full cut with feed returns correct value", DefaultCutConfig(), []byte{'\x1D', 'V', 'A', 0}},
+		{"partial cut with feed returns correct value", DefaultCutConfig().Mode("partial"), []byte{'\x1D', 'V', 'B', 0}},
+		{"full cut without feed returns correct value", DefaultCutConfig().Feed(false), []byte{'\x1D', 'V', '0'}},
+		{"partial cut without feed returns correct value", DefaultCutConfig().Mode("partial").Feed(false), []byte{'\x1D', 'V', '1'}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := profile.CutCommand(&testCase.cfg)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			gotAsBytes := []byte(got)
+
+			if !bytes.Equal(gotAsBytes, testCase.want) {
+				t.Errorf("CutCommand did not return expected bytes: wanted %v, got %v", testCase.want, gotAsBytes)
+			}
+		})
+	}
+
+	t.Run("invalid mode returns error", func(t *testing.T) {
+		cfg := DefaultCutConfig().Mode("perforated")
+		got, err := profile.CutCommand(&cfg)
+
+		if got != "" || !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("CutCommand did not return config error, got %q and %v", got, err)
+		}
+	})
+}
+
+func TestEpsonTMT20III_StyleCommands(t *testing.T) {
+	profile := EpsonTMT20III{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(*FormatConfig) (string, error)
+		fmtCfg      FormatConfig
+		want        string
+	}{
+		{"reverse on returns correct value", profile.ReverseCommand, DefaultFormatConfig().Reverse(true), "\x1DB1"},
+		{"reverse off returns correct value", profile.ReverseCommand, DefaultFormatConfig(), "\x1DB0"},
+		{"double-strike on returns correct value", profile.DoubleStrikeCommand, DefaultFormatConfig().DoubleStrike(true), "\x1BG1"},
+		{"double-strike off returns correct value", profile.DoubleStrikeCommand, DefaultFormatConfig(), "\x1BG0"},
+		{"upside-down on returns correct value", profile.UpsideDownCommand, DefaultFormatConfig().UpsideDown(true), "\x1B{1"},
+		{"upside-down off returns correct value", profile.UpsideDownCommand, DefaultFormatConfig(), "\x1B{0"},
+		{"rotation on returns correct value", profile.RotationCommand, DefaultFormatConfig().Rotate(true), "\x1BV1"},
+		{"rotation off returns correct value", profile.RotationCommand, DefaultFormatConfig(), "\x1BV0"},
+		{"smoothing on returns correct value", profile.SmoothingCommand, DefaultFormatConfig().Smoothing(true), "\x1Db1"},
+		{"smoothing off returns correct value", profile.SmoothingCommand, DefaultFormatConfig(), "\x1Db0"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.fmtCfg)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("command did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+}
+
 func TestEpsonTMT20III_FontCommand(t *testing.T) {
 	var profile Profile = EpsonTMT20III{}
 
@@ -247,7 +324,7 @@ func TestEpsonTMT20III_CharSizeCommand(t *testing.T) {
 				t.Errorf("returned command was not nil, expected empty string and error")
 			}
 
-			expectedError := fmt.Sprintf("invalid charsize options in FormatConfig: width %v, height %v\n", testCase.width, testCase.height)
+			expectedError := fmt.Sprintf("invalid charsize options in FormatConfig: width %v, height %v", testCase.width, testCase.height)
 
 			if err.Error() != expectedError {
 				t.Errorf("CharSizeCommand did not return expected error, got %s, wanted %s", err.Error(), expectedError)
@@ -291,7 +368,7 @@ func TestEpsonTMT20III_SelectQrCodeModelCommand(t *testing.T) {
 			t.Errorf("returned command was not nil, expected empty string and error")
 		}
 
-		expectedError := fmt.Sprintf("invalid model in QrCodeConfig: %v\n", "micro")
+		expectedError := fmt.Sprintf("invalid model in QrCodeConfig: %v", "micro")
 
 		if err.Error() != expectedError {
 			t.Errorf("SelectQrCodeModelCommand did not return expected error, got %s, wanted %s", err.Error(), expectedError)
@@ -344,7 +421,7 @@ func TestEpsonTMT20III_SetQrCodeSizeCommand(t *testing.T) {
 				t.Errorf("returned command was not nil, expected empty string and error")
 			}
 
-			expectedError := fmt.Sprintf("invalid size option in QrCodeConfig: %v\n", testCase.size)
+			expectedError := fmt.Sprintf("invalid size option in QrCodeConfig: %v", testCase.size)
 
 			if err.Error() != expectedError {
 				t.Errorf("SetQrCodeSizeCommand did not return expected error, got %s, wanted %s", err.Error(), expectedError)
@@ -399,7 +476,7 @@ func TestEpsonTMT20III_SelectQrCodeErrorCorrectionLevelCommand(t *testing.T) {
 				t.Errorf("returned command was not nil, expected empty string and error")
 			}
 
-			expectedError := fmt.Sprintf("invalid error correction level option in QrCodeConfig: %v\n", testCase.level)
+			expectedError := fmt.Sprintf("invalid error correction level option in QrCodeConfig: %v", testCase.level)
 
 			if err.Error() != expectedError {
 				t.Errorf("SelectQrCodeErrorCorrectionLevelCommand did not return expected error, got %s, wanted %s", err.Error(), expectedError)
@@ -444,10 +521,1034 @@ func TestEpsonTMT20III_StoreQrCodeDataCommand(t *testing.T) {
 			t.Errorf("returned command was not nil, expected empty string and error")
 		}
 
-		expectedError := fmt.Sprintf("maximum data length exceeded: %v > 7086 (max)\n", len(data))
+		expectedError := fmt.Sprintf("maximum data length exceeded: %v > 7086 (max)", len(data))
 
 		if err.Error() != expectedError {
 			t.Errorf("StoreQrCodeDataCommand did not return expected error, got %s, wanted %s", err.Error(), expectedError)
 		}
 	})
 }
+
+func TestEpsonTMT20III_CodeTables(t *testing.T) {
+	var profile CodeTables = EpsonTMT20III{}
+
+	for _, name := range profile.CodeTables() {
+		t.Run(name+" is a known code table", func(t *testing.T) {
+			if _, ok := codeTables[name]; !ok {
+				t.Errorf("CodeTables returned unknown code table %v", name)
+			}
+		})
+	}
+
+	t.Run("CP858 returns correct value", func(t *testing.T) {
+		got, err := profile.SelectCodeTableCommand("CP858")
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := []byte{'\x1B', 't', 19}
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("SelectCodeTableCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+
+	t.Run("unknown code table returns error", func(t *testing.T) {
+		got, err := profile.SelectCodeTableCommand("CP999")
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
+
+func TestEpsonTMT20III_RasterImageCommand(t *testing.T) {
+	var profile RasterImage = EpsonTMT20III{}
+
+	t.Run("raster image command returns correct value", func(t *testing.T) {
+		bitmap := NewBitmap(10, 2)
+		bitmap.SetBlack(0, 0, true)
+		bitmap.SetBlack(9, 1, true)
+
+		got, err := profile.RasterImageCommand(bitmap)
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := []byte{'\x1D', 'v', '0', 0, 2, 0, 2, 0, 0x80, 0x00, 0x00, 0x40}
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("RasterImageCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+
+	t.Run("raster image taller than band height returns error", func(t *testing.T) {
+		got, err := profile.RasterImageCommand(NewBitmap(8, profile.MaxRasterBandHeight()+1))
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
+
+func TestEpsonTMT20III_PageModeCommands(t *testing.T) {
+	var profile PageMode = EpsonTMT20III{}
+
+	cases := []struct {
+		name        string
+		commandFunc func() (string, error)
+		want        []byte
+	}{
+		{"enter page mode returns correct value", profile.EnterPageModeCommand, []byte{'\x1B', 'L'}},
+		{"page area returns correct value", func() (string, error) { return profile.SetPageAreaCommand(8, 0, 512, 300) },
+			[]byte{'\x1B', 'W', 8, 0, 0, 0, 0, 2, 0x2C, 1}},
+		{"page position returns correct value", func() (string, error) { return profile.SetPagePositionCommand(300, 2) },
+			[]byte{'\x1B', '$', 0x2C, 1, '\x1D', '$', 2, 0}},
+		{"print page returns correct value", profile.PrintPageCommand, []byte{'\x0C'}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc()
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if !bytes.Equal([]byte(got), testCase.want) {
+				t.Errorf("command did not return expected bytes: wanted %v, got %v", testCase.want, []byte(got))
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name        string
+		commandFunc func() (string, error)
+	}{
+		{"empty page area returns error", func() (string, error) { return profile.SetPageAreaCommand(0, 0, 0, 100) }},
+		{"negative page position returns error", func() (string, error) { return profile.SetPagePositionCommand(-1, 0) }},
+		{"page position beyond 65535 returns error", func() (string, error) { return profile.SetPagePositionCommand(0, 65536) }},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc()
+
+			if got != "" || err == nil {
+				t.Errorf("returned command was not nil, expected empty string and error")
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_BarcodeSettingCommands(t *testing.T) {
+	var profile Barcode = EpsonTMT20III{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(*BarcodeConfig) (string, error)
+		cfg         BarcodeConfig
+		want        []byte
+	}{
+		{"barcode width 2 returns correct value", profile.SetBarcodeWidthCommand, BarcodeConfig{width: 2}, []byte{'\x1D', 'w', 2}},
+		{"barcode width 6 returns correct value", profile.SetBarcodeWidthCommand, BarcodeConfig{width: 6}, []byte{'\x1D', 'w', 6}},
+		{"barcode height 1 returns correct value", profile.SetBarcodeHeightCommand, BarcodeConfig{height: 1}, []byte{'\x1D', 'h', 1}},
+		{"barcode height 255 returns correct value", profile.SetBarcodeHeightCommand, BarcodeConfig{height: 255}, []byte{'\x1D', 'h', 255}},
+		{"hri position none returns correct value", profile.SelectHriPositionCommand, BarcodeConfig{hriPosition: "none"}, []byte{'\x1D', 'H', '0'}},
+		{"hri position above returns correct value", profile.SelectHriPositionCommand, BarcodeConfig{hriPosition: "above"}, []byte{'\x1D', 'H', '1'}},
+		{"hri position below returns correct value", profile.SelectHriPositionCommand, BarcodeConfig{hriPosition: "below"}, []byte{'\x1D', 'H', '2'}},
+		{"hri position both returns correct value", profile.SelectHriPositionCommand, BarcodeConfig{hriPosition: "both"}, []byte{'\x1D', 'H', '3'}},
+		{"hri font A returns correct value", profile.SelectHriFontCommand, BarcodeConfig{hriFont: "A"}, []byte{'\x1D', 'f', '0'}},
+		{"hri font B returns correct value", profile.SelectHriFontCommand, BarcodeConfig{hriFont: "B"}, []byte{'\x1D', 'f', '1'}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			gotAsBytes := []byte(got)
+
+			if !bytes.Equal(gotAsBytes, testCase.want) {
+				t.Errorf("command did not return expected bytes: wanted %v, got %v", testCase.want, gotAsBytes)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name        string
+		commandFunc func(*BarcodeConfig) (string, error)
+		cfg         BarcodeConfig
+		wantError   string
+	}{
+		{"barcode width 1 returns error", profile.SetBarcodeWidthCommand, BarcodeConfig{width: 1}, "invalid width option in BarcodeConfig: 1"},
+		{"barcode width 7 returns error", profile.SetBarcodeWidthCommand, BarcodeConfig{width: 7}, "invalid width option in BarcodeConfig: 7"},
+		{"barcode height 0 returns error", profile.SetBarcodeHeightCommand, BarcodeConfig{height: 0}, "invalid height option in BarcodeConfig: 0"},
+		{"hri position unknown returns error", profile.SelectHriPositionCommand, BarcodeConfig{hriPosition: "left"}, "invalid HRI position option in BarcodeConfig: left"},
+		{"hri font unknown returns error", profile.SelectHriFontCommand, BarcodeConfig{hriFont: "C"}, "invalid HRI font option in BarcodeConfig: C"},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if got != "" || err == nil {
+				t.Fatalf("returned command was not nil, expected empty string and error")
+			}
+
+			if err.Error() != testCase.wantError {
+				t.Errorf("command did not return expected error, got %s, wanted %s", err.Error(), testCase.wantError)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_PrintBarcodeCommand(t *testing.T) {
+	var profile Barcode = EpsonTMT20III{}
+
+	cases := []struct {
+		name      string
+		symbology string
+		data      string
+		want      []byte
+	}{
+		{"UPC-A barcode returns correct value", "UPC-A", "03600029145", append([]byte{'\x1D', 'k', 65, 11}, "03600029145"...)},
+		{"UPC-E barcode returns correct value", "UPC-E", "0123456", append([]byte{'\x1D', 'k', 66, 7}, "0123456"...)},
+		{"EAN-13 barcode returns correct value", "EAN-13", "4006381333931", append([]byte{'\x1D', 'k', 67, 13}, "4006381333931"...)},
+		{"EAN-8 barcode returns correct value", "EAN-8", "9638507", append([]byte{'\x1D', 'k', 68, 7}, "9638507"...)},
+		{"CODE39 barcode returns correct value", "CODE39", "ABC-1", append([]byte{'\x1D', 'k', 69, 5}, "ABC-1"...)},
+		{"ITF barcode returns correct value", "ITF", "1234", append([]byte{'\x1D', 'k', 70, 4}, "1234"...)},
+		{"CODABAR barcode returns correct value", "CODABAR", "A123B", append([]byte{'\x1D', 'k', 71, 5}, "A123B"...)},
+		{"CODE93 barcode returns correct value", "CODE93", "Ab1", append([]byte{'\x1D', 'k', 72, 3}, "Ab1"...)},
+		{"CODE128 barcode returns correct value", "CODE128", "Ab{1", append([]byte{'\x1D', 'k', 73, 7}, "{BAb{{1"...)},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := profile.PrintBarcodeCommand(testCase.data, &BarcodeConfig{symbology: testCase.symbology})
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			gotAsBytes := []byte(got)
+
+			if !bytes.Equal(gotAsBytes, testCase.want) {
+				t.Errorf("PrintBarcodeCommand did not return expected bytes: wanted %v, got %v", testCase.want, gotAsBytes)
+			}
+		})
+	}
+
+	t.Run("unknown symbology returns error", func(t *testing.T) {
+		got, err := profile.PrintBarcodeCommand("123", &BarcodeConfig{symbology: "PLESSEY"})
+
+		if got != "" || err == nil {
+			t.Fatalf("returned command was not nil, expected empty string and error")
+		}
+
+		expectedError := "invalid symbology option in BarcodeConfig: PLESSEY"
+
+		if err.Error() != expectedError {
+			t.Errorf("PrintBarcodeCommand did not return expected error, got %s, wanted %s", err.Error(), expectedError)
+		}
+	})
+}
+
+func TestEpsonTMT20III_Pdf417Commands(t *testing.T) {
+	var profile Pdf417 = EpsonTMT20III{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(*Pdf417Config) (string, error)
+		cfg         Pdf417Config
+		want        []byte
+	}{
+		{"pdf417 columns 0 returns correct value", profile.SetPdf417ColumnsCommand, DefaultPdf417Config(), []byte{'\x1D', '(', 'k', 3, 0, 48, 65, 0}},
+		{"pdf417 columns 30 returns correct value", profile.SetPdf417ColumnsCommand, DefaultPdf417Config().Columns(30), []byte{'\x1D', '(', 'k', 3, 0, 48, 65, 30}},
+		{"pdf417 rows 0 returns correct value", profile.SetPdf417RowsCommand, DefaultPdf417Config(), []byte{'\x1D', '(', 'k', 3, 0, 48, 66, 0}},
+		{"pdf417 rows 90 returns correct value", profile.SetPdf417RowsCommand, DefaultPdf417Config().Rows(90), []byte{'\x1D', '(', 'k', 3, 0, 48, 66, 90}},
+		{"pdf417 module width 2 returns correct value", profile.SetPdf417ModuleWidthCommand, DefaultPdf417Config().ModuleWidth(2), []byte{'\x1D', '(', 'k', 3, 0, 48, 67, 2}},
+		{"pdf417 row height 8 returns correct value", profile.SetPdf417RowHeightCommand, DefaultPdf417Config().RowHeight(8), []byte{'\x1D', '(', 'k', 3, 0, 48, 68, 8}},
+		{"pdf417 error correction level 5 returns correct value", profile.SelectPdf417ErrorCorrectionLevelCommand, DefaultPdf417Config().ErrorCorrectionLevel(5), []byte{'\x1D', '(', 'k', 4, 0, 48, 69, 48, 53}},
+		{"pdf417 error correction ratio 40 returns correct value", profile.SelectPdf417ErrorCorrectionLevelCommand, DefaultPdf417Config().ErrorCorrectionRatio(40), []byte{'\x1D', '(', 'k', 4, 0, 48, 69, 49, 40}},
+		{"pdf417 standard option returns correct value", profile.SelectPdf417OptionsCommand, DefaultPdf417Config(), []byte{'\x1D', '(', 'k', 3, 0, 48, 70, 0}},
+		{"pdf417 truncated option returns correct value", profile.SelectPdf417OptionsCommand, DefaultPdf417Config().Truncated(true), []byte{'\x1D', '(', 'k', 3, 0, 48, 70, 1}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			gotAsBytes := []byte(got)
+
+			if !bytes.Equal(gotAsBytes, testCase.want) {
+				t.Errorf("command did not return expected bytes: wanted %v, got %v", testCase.want, gotAsBytes)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name        string
+		commandFunc func(*Pdf417Config) (string, error)
+		cfg         Pdf417Config
+		wantError   string
+	}{
+		{"pdf417 columns 31 returns error", profile.SetPdf417ColumnsCommand, DefaultPdf417Config().Columns(31), "invalid columns option in Pdf417Config: 31"},
+		{"pdf417 rows 2 returns error", profile.SetPdf417RowsCommand, DefaultPdf417Config().Rows(2), "invalid rows option in Pdf417Config: 2"},
+		{"pdf417 module width 9 returns error", profile.SetPdf417ModuleWidthCommand, DefaultPdf417Config().ModuleWidth(9), "invalid module width option in Pdf417Config: 9"},
+		{"pdf417 row height 1 returns error", profile.SetPdf417RowHeightCommand, DefaultPdf417Config().RowHeight(1), "invalid row height option in Pdf417Config: 1"},
+		{"pdf417 error correction level 9 returns error", profile.SelectPdf417ErrorCorrectionLevelCommand, DefaultPdf417Config().ErrorCorrectionLevel(9), "invalid error correction level option in Pdf417Config: 9"},
+		{"pdf417 error correction ratio 0 returns error", profile.SelectPdf417ErrorCorrectionLevelCommand, DefaultPdf417Config().ErrorCorrectionRatio(0), "invalid error correction ratio option in Pdf417Config: 0"},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if got != "" || err == nil {
+				t.Fatalf("returned command was not nil, expected empty string and error")
+			}
+
+			if err.Error() != testCase.wantError {
+				t.Errorf("command did not return expected error, got %s, wanted %s", err.Error(), testCase.wantError)
+			}
+		})
+	}
+
+	t.Run("pdf417 store data command returns correct value", func(t *testing.T) {
+		data := strings.Repeat("a", 300)
+		got, err := profile.StorePdf417DataCommand(data)
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := append([]byte{'\x1D', '(', 'k', 47, 1, 48, 80, 48}, data...)
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("StorePdf417DataCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+}
+
+func TestEpsonTMT20III_MaxiCodeCommands(t *testing.T) {
+	var profile MaxiCode = EpsonTMT20III{}
+
+	t.Run("maxicode mode 4 returns correct value", func(t *testing.T) {
+		got, err := profile.SelectMaxiCodeModeCommand(&MaxiCodeConfig{mode: 4})
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := []byte{'\x1D', '(', 'k', 3, 0, 50, 65, 52}
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("SelectMaxiCodeModeCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+
+	t.Run("maxicode mode 7 returns error", func(t *testing.T) {
+		got, err := profile.SelectMaxiCodeModeCommand(&MaxiCodeConfig{mode: 7})
+
+		if got != "" || err == nil {
+			t.Fatalf("returned command was not nil, expected empty string and error")
+		}
+
+		if err.Error() != "invalid mode option in MaxiCodeConfig: 7" {
+			t.Errorf("SelectMaxiCodeModeCommand did not return expected error, got %s", err.Error())
+		}
+	})
+
+	t.Run("maxicode store data command returns correct value", func(t *testing.T) {
+		got, err := profile.StoreMaxiCodeDataCommand("abc")
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := []byte{'\x1D', '(', 'k', 6, 0, 50, 80, 48, 'a', 'b', 'c'}
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("StoreMaxiCodeDataCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+}
+
+func TestEpsonTMT20III_StoreQrCodeDataCommand_LargeData(t *testing.T) {
+	var profile Profile = EpsonTMT20III{}
+
+	data := strings.Repeat("a", 300)
+	got, err := profile.StoreQrCodeDataCommand(data)
+
+	if err != nil {
+		t.Errorf("err was not nil")
+	}
+
+	want := append([]byte{'\x1D', '(', 'k', 47, 1, 49, 80, 48}, data...)
+
+	if !bytes.Equal([]byte(got), want) {
+		t.Errorf("StoreQrCodeDataCommand did not encode data length in pL and pH: wanted %v, got %v", want[:8], []byte(got)[:8])
+	}
+}
+
+func TestEpsonTMT20III_SelectInternationalCharacterSetCommand(t *testing.T) {
+	var profile InternationalCharacterSet = EpsonTMT20III{}
+
+	for set := range internationalCharacterSets {
+		t.Run(set+" is supported", func(t *testing.T) {
+			if _, err := profile.SelectInternationalCharacterSetCommand(set); err != nil {
+				t.Errorf("SelectInternationalCharacterSetCommand returned error: %v", err)
+			}
+		})
+	}
+
+	t.Run("UK returns correct value", func(t *testing.T) {
+		got, err := profile.SelectInternationalCharacterSetCommand("UK")
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := []byte{'\x1B', 'R', 3}
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("SelectInternationalCharacterSetCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+
+	t.Run("unknown set returns error", func(t *testing.T) {
+		got, err := profile.SelectInternationalCharacterSetCommand("Atlantis")
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
+
+func TestEpsonTMT20III_MultiByteEncodings(t *testing.T) {
+	t.Run("models without multi-byte characters return no encodings", func(t *testing.T) {
+		if got := (EpsonTMT20III{}).MultiByteEncodings(); len(got) != 0 {
+			t.Errorf("MultiByteEncodings returned %v, wanted none", got)
+		}
+	})
+
+	cases := []struct {
+		encoding string
+		enable   string
+		disable  string
+	}{
+		{"Shift-JIS", "\x1CC\x01\x1C&", "\x1C.\x1CC\x00"},
+		{"GB18030", "\x1C&", "\x1C."},
+		{"Big5", "\x1C&", "\x1C."},
+		{"KS C 5601", "\x1C&", "\x1C."},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.encoding+" returns correct values", func(t *testing.T) {
+			profile := EpsonTMT20III{Encoding: testCase.encoding}
+
+			if got := profile.MultiByteEncodings(); len(got) != 1 || got[0] != testCase.encoding {
+				t.Errorf("MultiByteEncodings returned %v, wanted [%v]", got, testCase.encoding)
+			}
+
+			if _, ok := multiByteTables[testCase.encoding]; !ok {
+				t.Errorf("%v is not a known multi-byte encoding", testCase.encoding)
+			}
+
+			enable, err := profile.EnableMultiByteCommand(testCase.encoding)
+
+			if err != nil || enable != testCase.enable {
+				t.Errorf("EnableMultiByteCommand did not return expected bytes: wanted %q, got %q, err %v", testCase.enable, enable, err)
+			}
+
+			disable, err := profile.DisableMultiByteCommand(testCase.encoding)
+
+			if err != nil || disable != testCase.disable {
+				t.Errorf("DisableMultiByteCommand did not return expected bytes: wanted %q, got %q, err %v", testCase.disable, disable, err)
+			}
+		})
+	}
+
+	t.Run("encoding the firmware lacks returns error", func(t *testing.T) {
+		got, err := EpsonTMT20III{Encoding: "Shift-JIS"}.EnableMultiByteCommand("Big5")
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
+
+func TestEpsonTMT20III_SelectUTF8Command(t *testing.T) {
+	cases := []struct {
+		name     string
+		priority string
+		want     string
+	}{
+		{"no priority selects UTF-8 only", "", "\x1C(C\x02\x000\x02"},
+		{"Japanese priority", "Japanese", "\x1C(C\x02\x000\x02\x1C(C\x03\x00<\x00\x0B"},
+		{"Simplified Chinese priority", "Simplified Chinese", "\x1C(C\x02\x000\x02\x1C(C\x03\x00<\x00\x14"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := EpsonTMT20III{UTF8: true}.SelectUTF8Command(testCase.priority)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("SelectUTF8Command did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+
+	t.Run("firmware without UTF-8 returns unsupported error", func(t *testing.T) {
+		_, err := EpsonTMT20III{}.SelectUTF8Command("")
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("SelectUTF8Command did not return unsupported error, got %v", err)
+		}
+	})
+
+	t.Run("unknown priority returns error", func(t *testing.T) {
+		got, err := EpsonTMT20III{UTF8: true}.SelectUTF8Command("Klingon")
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
+
+func TestEpsonTMT20III_StatusCommand(t *testing.T) {
+	cases := []struct {
+		kind string
+		want string
+	}{
+		{"printer", "\x10\x04\x01"},
+		{"offline", "\x10\x04\x02"},
+		{"error", "\x10\x04\x03"},
+		{"paper", "\x10\x04\x04"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.kind+" returns correct value", func(t *testing.T) {
+			got, err := EpsonTMT20III{}.StatusCommand(testCase.kind)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("StatusCommand did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+
+	t.Run("unknown status returns error", func(t *testing.T) {
+		got, err := EpsonTMT20III{}.StatusCommand("ink")
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
+
+func TestEpsonTMT20III_DecodeStatus(t *testing.T) {
+	cases := []struct {
+		name  string
+		kind  string
+		reply byte
+		want  Status
+	}{
+		{"printer online", "printer", 0x12, Status{}},
+		{"printer offline with drawer pin high", "printer", 0x1E, Status{DrawerPinHigh: true, Offline: true}},
+		{"feed button pressed", "printer", 0x52, Status{FeedButtonPressed: true}},
+		{"cover open", "offline", 0x16, Status{CoverOpen: true}},
+		{"stopped by paper end with error", "offline", 0x72, Status{PaperEnd: true, Error: true}},
+		{"paper being fed", "offline", 0x1A, Status{PaperFeeding: true}},
+		{"cutter error", "error", 0x1A, Status{CutterError: true}},
+		{"unrecoverable and recoverable errors", "error", 0x72, Status{UnrecoverableError: true, AutoRecoverableError: true}},
+		{"paper near end", "paper", 0x1E, Status{PaperNearEnd: true}},
+		{"paper end", "paper", 0x72, Status{PaperEnd: true}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got Status
+			err := EpsonTMT20III{}.DecodeStatus(testCase.kind, testCase.reply, &got)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("DecodeStatus did not return expected status: wanted %+v, got %+v", testCase.want, got)
+			}
+		})
+	}
+
+	t.Run("reply which is not a status returns status error", func(t *testing.T) {
+		var got Status
+		err := EpsonTMT20III{}.DecodeStatus("paper", 0x00, &got)
+
+		if !errors.Is(err, ErrInvalidStatus) {
+			t.Errorf("DecodeStatus did not return status error, got %v", err)
+		}
+	})
+}
+
+func TestEpsonTMT20III_AutomaticStatusBack(t *testing.T) {
+	profile := EpsonTMT20III{}
+
+	t.Run("enable and disable return correct values", func(t *testing.T) {
+		enable, _ := profile.EnableAutomaticStatusBackCommand()
+		disable, _ := profile.DisableAutomaticStatusBackCommand()
+
+		if enable != "\x1Da\x0F" || disable != "\x1Da\x00" {
+			t.Errorf("commands did not return expected bytes, got %q and %q", enable, disable)
+		}
+	})
+
+	t.Run("status messages are told apart from status replies", func(t *testing.T) {
+		if profile.AutomaticStatusBackLength(0x10) != 4 || profile.AutomaticStatusBackLength(0x3C) != 4 {
+			t.Errorf("status message was not recognised")
+		}
+
+		if profile.AutomaticStatusBackLength(0x12) != 0 || profile.AutomaticStatusBackLength(0x5F) != 0 {
+			t.Errorf("status reply was recognised as status message")
+		}
+	})
+
+	cases := []struct {
+		name    string
+		message []byte
+		want    Status
+	}{
+		{"nothing to report", []byte{0x10, 0x00, 0x00, 0x00}, Status{}},
+		{"cover open and offline", []byte{0x38, 0x00, 0x00, 0x00}, Status{CoverOpen: true, Offline: true}},
+		{"cutter error", []byte{0x10, 0x08, 0x00, 0x00}, Status{CutterError: true, Error: true}},
+		{"paper near end", []byte{0x10, 0x00, 0x03, 0x00}, Status{PaperNearEnd: true}},
+		{"paper end", []byte{0x10, 0x00, 0x0F, 0x00}, Status{PaperNearEnd: true, PaperEnd: true}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got Status
+			err := profile.DecodeAutomaticStatusBack(testCase.message, &got)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("DecodeAutomaticStatusBack did not return expected status: wanted %+v, got %+v", testCase.want, got)
+			}
+		})
+	}
+
+	t.Run("message which is not a status returns status error", func(t *testing.T) {
+		var got Status
+		err := profile.DecodeAutomaticStatusBack([]byte{0x10, 0x80, 0x00, 0x00}, &got)
+
+		if !errors.Is(err, ErrInvalidStatus) {
+			t.Errorf("DecodeAutomaticStatusBack did not return status error, got %v", err)
+		}
+	})
+}
+
+func TestEpsonTMT20III_ProcessID(t *testing.T) {
+	profile := EpsonTMT20III{}
+
+	t.Run("command returns correct value", func(t *testing.T) {
+		got, err := profile.ProcessIDCommand("0427")
+
+		if err != nil || got != "\x1D(H\x06\x00000427" {
+			t.Errorf("ProcessIDCommand did not return expected bytes, got %q, err %v", got, err)
+		}
+	})
+
+	for _, id := range []string{"", "123", "12345", "12a4"} {
+		t.Run("invalid process ID "+id+" returns error", func(t *testing.T) {
+			_, err := profile.ProcessIDCommand(id)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("ProcessIDCommand did not return config error, got %v", err)
+			}
+		})
+	}
+
+	t.Run("responses are told apart from status replies", func(t *testing.T) {
+		if profile.ProcessIDResponseLength(0x37) != 7 {
+			t.Errorf("process ID response was not recognised")
+		}
+
+		if profile.ProcessIDResponseLength(0x12) != 0 || profile.ProcessIDResponseLength(0x10) != 0 {
+			t.Errorf("status was recognised as process ID response")
+		}
+	})
+
+	t.Run("response is decoded", func(t *testing.T) {
+		got, err := profile.DecodeProcessIDResponse([]byte("\x37\x220427\x00"))
+
+		if err != nil || got != "0427" {
+			t.Errorf("DecodeProcessIDResponse did not return expected ID, got %q, err %v", got, err)
+		}
+	})
+
+	negativeCases := []struct {
+		name     string
+		response []byte
+	}{
+		{"short response", []byte("\x37\x22042\x00")},
+		{"other response", []byte("\x37\x230427\x00")},
+		{"unterminated response", []byte("\x37\x2204271")},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name+" returns status error", func(t *testing.T) {
+			_, err := profile.DecodeProcessIDResponse(testCase.response)
+
+			if !errors.Is(err, ErrInvalidStatus) {
+				t.Errorf("DecodeProcessIDResponse did not return status error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_Identification(t *testing.T) {
+	profile := EpsonTMT20III{}
+
+	commands := []struct {
+		kind string
+		want string
+	}{
+		{"model ID", "\x1DI\x01"},
+		{"type ID", "\x1DI\x02"},
+		{"firmware version", "\x1DIA"},
+		{"manufacturer", "\x1DIB"},
+		{"model name", "\x1DIC"},
+		{"serial number", "\x1DID"},
+	}
+
+	for _, testCase := range commands {
+		t.Run(testCase.kind+" command returns correct value", func(t *testing.T) {
+			got, err := profile.IdentificationCommand(testCase.kind)
+
+			if err != nil || got != testCase.want {
+				t.Errorf("IdentificationCommand did not return expected bytes: wanted %q, got %q, err %v", testCase.want, got, err)
+			}
+		})
+	}
+
+	t.Run("unknown information returns error", func(t *testing.T) {
+		_, err := profile.IdentificationCommand("colour")
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("IdentificationCommand did not return config error, got %v", err)
+		}
+	})
+
+	t.Run("blocks are recognised by their header", func(t *testing.T) {
+		if !profile.IdentificationBlock(0x5F) || profile.IdentificationBlock(0x12) {
+			t.Errorf("IdentificationBlock did not recognise block header")
+		}
+	})
+
+	cases := []struct {
+		name  string
+		kind  string
+		reply string
+		want  PrinterInfo
+	}{
+		{"model ID", "model ID", "\x20", PrinterInfo{ModelID: 0x20}},
+		{"type ID", "type ID", "\x03", PrinterInfo{TypeID: 0x03}},
+		{"firmware version", "firmware version", "_30.01 ESC/POS\x00", PrinterInfo{FirmwareVersion: "30.01 ESC/POS"}},
+		{"manufacturer", "manufacturer", "_EPSON\x00", PrinterInfo{Manufacturer: "EPSON"}},
+		{"model name", "model name", "_TM-T20III\x00", PrinterInfo{ModelName: "TM-T20III"}},
+		{"serial number", "serial number", "_X7P0001234\x00", PrinterInfo{SerialNumber: "X7P0001234"}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name+" is decoded", func(t *testing.T) {
+			var got PrinterInfo
+			err := profile.DecodeIdentification(testCase.kind, []byte(testCase.reply), &got)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("DecodeIdentification did not return expected information: wanted %+v, got %+v", testCase.want, got)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name  string
+		kind  string
+		reply string
+	}{
+		{"type ID with bit 4 set", "type ID", "\x12"},
+		{"block for model ID", "model ID", "_TM\x00"},
+		{"block without header", "model name", "TM-T20III\x00"},
+		{"byte for model name", "model name", "\x20"},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name+" returns status error", func(t *testing.T) {
+			var got PrinterInfo
+			err := profile.DecodeIdentification(testCase.kind, []byte(testCase.reply), &got)
+
+			if !errors.Is(err, ErrInvalidStatus) {
+				t.Errorf("DecodeIdentification did not return status error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_DrawerPulseCommand(t *testing.T) {
+	cases := []struct {
+		name    string
+		pin     int
+		onTime  time.Duration
+		offTime time.Duration
+		want    string
+	}{
+		{"pin 2", 2, 100 * time.Millisecond, 200 * time.Millisecond, "\x1Bp\x00\x32\x64"},
+		{"pin 5", 5, 100 * time.Millisecond, 200 * time.Millisecond, "\x1Bp\x01\x32\x64"},
+		{"times are rounded to 2 ms", 2, 3 * time.Millisecond, 509 * time.Millisecond, "\x1Bp\x00\x02\xFF"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := EpsonTMT20III{}.DrawerPulseCommand(testCase.pin, testCase.onTime, testCase.offTime)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("DrawerPulseCommand did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name    string
+		pin     int
+		onTime  time.Duration
+		offTime time.Duration
+	}{
+		{"pin 3", 3, 100 * time.Millisecond, 100 * time.Millisecond},
+		{"no on time", 2, 0, 100 * time.Millisecond},
+		{"no off time", 2, 100 * time.Millisecond, 0},
+		{"on time too long", 2, time.Second, 100 * time.Millisecond},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name+" returns error", func(t *testing.T) {
+			_, err := EpsonTMT20III{}.DrawerPulseCommand(testCase.pin, testCase.onTime, testCase.offTime)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("DrawerPulseCommand did not return config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_RealTimeDrawerPulseCommand(t *testing.T) {
+	cases := []struct {
+		name  string
+		pin   int
+		pulse time.Duration
+		want  string
+	}{
+		{"pin 2", 2, 100 * time.Millisecond, "\x10\x14\x01\x00\x01"},
+		{"pin 5", 5, 800 * time.Millisecond, "\x10\x14\x01\x01\x08"},
+		{"pulse is rounded to 100 ms", 2, 230 * time.Millisecond, "\x10\x14\x01\x00\x02"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := EpsonTMT20III{}.RealTimeDrawerPulseCommand(testCase.pin, testCase.pulse)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("RealTimeDrawerPulseCommand did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name  string
+		pin   int
+		pulse time.Duration
+	}{
+		{"pin 0", 0, 100 * time.Millisecond},
+		{"no pulse", 2, 0},
+		{"pulse too long", 2, time.Second},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name+" returns error", func(t *testing.T) {
+			_, err := EpsonTMT20III{}.RealTimeDrawerPulseCommand(testCase.pin, testCase.pulse)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("RealTimeDrawerPulseCommand did not return config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_FeedAndSpacingCommands(t *testing.T) {
+	profile := EpsonTMT20III{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(int) (string, error)
+		value       int
+		want        string
+	}{
+		{"feed 3 lines returns correct value", profile.FeedLinesCommand, 3, "\x1Bd\x03"},
+		{"feed 24 dots returns correct value", profile.FeedDotsCommand, 24, "\x1BJ\x18"},
+		{"feed 300 dots is split", profile.FeedDotsCommand, 300, "\x1BJ\xFF\x1BJ\x2D"},
+		{"feed 0 dots returns nothing", profile.FeedDotsCommand, 0, ""},
+		{"line spacing 24 dots returns correct value", profile.LineSpacingCommand, 24, "\x1B3\x18"},
+		{"character spacing 2 dots returns correct value", profile.CharSpacingCommand, 2, "\x1B \x02"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(testCase.value)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("command did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+
+	t.Run("default line spacing returns correct value", func(t *testing.T) {
+		got, _ := profile.DefaultLineSpacingCommand()
+
+		if got != "\x1B2" {
+			t.Errorf("DefaultLineSpacingCommand did not return expected bytes, got %q", got)
+		}
+	})
+
+	negativeCases := []struct {
+		name        string
+		commandFunc func(int) (string, error)
+		value       int
+	}{
+		{"feed 256 lines", profile.FeedLinesCommand, 256},
+		{"feed -1 lines", profile.FeedLinesCommand, -1},
+		{"feed -1 dots", profile.FeedDotsCommand, -1},
+		{"line spacing 256 dots", profile.LineSpacingCommand, 256},
+		{"character spacing -1 dots", profile.CharSpacingCommand, -1},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name+" returns error", func(t *testing.T) {
+			_, err := testCase.commandFunc(testCase.value)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("command did not return config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_PositioningCommands(t *testing.T) {
+	profile := EpsonTMT20III{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(int) (string, error)
+		dots        int
+		want        string
+	}{
+		{"left margin returns correct value", profile.LeftMarginCommand, 40, "\x1DL\x28\x00"},
+		{"print area width returns correct value", profile.PrintAreaWidthCommand, 512, "\x1DW\x00\x02"},
+		{"absolute position returns correct value", profile.AbsolutePositionCommand, 400, "\x1B$\x90\x01"},
+		{"relative position returns correct value", profile.RelativePositionCommand, 24, "\x1B\\\x18\x00"},
+		{"negative relative position returns correct value", profile.RelativePositionCommand, -24, "\x1B\\\xE8\xFF"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(testCase.dots)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("command did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name        string
+		commandFunc func(int) (string, error)
+		dots        int
+	}{
+		{"negative left margin", profile.LeftMarginCommand, -1},
+		{"zero print area width", profile.PrintAreaWidthCommand, 0},
+		{"absolute position beyond 65535", profile.AbsolutePositionCommand, 0x10000},
+		{"relative position beyond 32767", profile.RelativePositionCommand, 0x8000},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name+" returns error", func(t *testing.T) {
+			_, err := testCase.commandFunc(testCase.dots)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("command did not return config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT20III_CutterPosition(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile Profile
+	}{
+		{"TM-T20III cutter is 15 mm above the print head", EpsonTMT20III{}},
+		{"TM-T88VII cutter is 15 mm above the print head", EpsonTMT88VII{}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			want, _ := Millimetres(15).dots(testCase.profile)
+			position := testCase.profile.(CutterPosition)
+
+			if got := position.CutterDistance(); got != want {
+				t.Errorf("CutterDistance did not return expected distance: wanted %v, got %v", want, got)
+			}
+
+			if !position.FeedsToCutter() {
+				t.Errorf("FeedsToCutter returned false for a cut command which feeds to the cutter")
+			}
+		})
+	}
+}
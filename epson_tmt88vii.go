@@ -0,0 +1,140 @@
+package escpos
+
+// EpsonTMT88VII implements the ESC/POS commands specific to the Epson
+// TM-T88VII printer. It supports every command of the TM-T20III, along
+// with DataMatrix and Aztec symbols and UTF-8 text.
+type EpsonTMT88VII struct {
+	EpsonTMT20III
+}
+
+// dataMatrixRectangles holds the column counts of each rectangular
+// DataMatrix size, keyed by row count.
+var dataMatrixRectangles = map[uint8][]uint8{
+	8:  {18, 32},
+	12: {26, 36},
+	16: {36, 48},
+}
+
+func (EpsonTMT88VII) PrintableWidth() int {
+	return 512
+}
+
+func (EpsonTMT88VII) DotsPerInch() int {
+	return 180
+}
+
+func (EpsonTMT88VII) CutterDistance() int {
+	// The cutter is about 15 mm above the print head.
+	return 106
+}
+
+func (EpsonTMT88VII) FeedDotsCommand(dots int) (string, error) {
+	// The vertical motion unit is 1/360 inch, half a dot.
+	return epsonFeedUnits(dots, 2)
+}
+
+func (EpsonTMT88VII) LineSpacingCommand(dots int) (string, error) {
+	return epsonLineSpacing(dots, 2)
+}
+
+func (profile EpsonTMT88VII) SelectUTF8Command(priority string) (string, error) {
+	profile.EpsonTMT20III.UTF8 = true
+	return profile.EpsonTMT20III.SelectUTF8Command(priority)
+}
+
+func (EpsonTMT88VII) SelectDataMatrixTypeCommand(cfg *DataMatrixConfig) (string, error) {
+	switch cfg.shape {
+	case "square":
+		valid := cfg.columns == cfg.rows && (cfg.columns == 0 ||
+			(cfg.columns >= 10 && cfg.columns <= 26 && cfg.columns%2 == 0) ||
+			(cfg.columns >= 32 && cfg.columns <= 52 && cfg.columns%4 == 0) ||
+			(cfg.columns >= 64 && cfg.columns <= 104 && cfg.columns%8 == 0) ||
+			cfg.columns == 120 || cfg.columns == 132 || cfg.columns == 144)
+		if !valid {
+			return "", invalidConfig("invalid square size option in DataMatrixConfig: %vx%v", cfg.columns, cfg.rows)
+		}
+		return symbolCommand(dataMatrixSymbol, 66, 0, cfg.rows, cfg.columns), nil
+	case "rectangle":
+		valid := cfg.columns == 0 && cfg.rows == 0
+		for _, columns := range dataMatrixRectangles[cfg.rows] {
+			valid = valid || columns == cfg.columns
+		}
+		if !valid {
+			return "", invalidConfig("invalid rectangle size option in DataMatrixConfig: %vx%v", cfg.columns, cfg.rows)
+		}
+		return symbolCommand(dataMatrixSymbol, 66, 1, cfg.rows, cfg.columns), nil
+	default:
+		return "", invalidConfig("invalid shape option in DataMatrixConfig: %v", cfg.shape)
+	}
+}
+
+func (EpsonTMT88VII) SetDataMatrixModuleSizeCommand(cfg *DataMatrixConfig) (string, error) {
+	if cfg.moduleSize < 2 || cfg.moduleSize > 16 {
+		return "", invalidConfig("invalid module size option in DataMatrixConfig: %v", cfg.moduleSize)
+	}
+
+	return symbolCommand(dataMatrixSymbol, 67, cfg.moduleSize), nil
+}
+
+func (EpsonTMT88VII) StoreDataMatrixDataCommand(data string, cfg *DataMatrixConfig) (string, error) {
+	// In GS1 mode FNC1 in first position is sent as a leading GS, the
+	// same character that separates variable length elements.
+	if cfg.gs1 {
+		data = gs1Separator + data
+	}
+
+	if len(data) < 1 || len(data) > 3116 {
+		return "", invalidConfig("invalid DataMatrix data length: %v", len(data))
+	}
+
+	return symbolCommand(dataMatrixSymbol, 80, append([]byte{48}, data...)...), nil
+}
+
+func (EpsonTMT88VII) PrintDataMatrixDataCommand() (string, error) {
+	return symbolCommand(dataMatrixSymbol, 81, 48), nil
+}
+
+func (EpsonTMT88VII) SelectAztecModeCommand(cfg *AztecConfig) (string, error) {
+	switch cfg.mode {
+	case "full":
+		if cfg.layers > 32 {
+			return "", invalidConfig("invalid layers option in AztecConfig: %v", cfg.layers)
+		}
+		return symbolCommand(aztecSymbol, 65, 0, cfg.layers), nil
+	case "compact":
+		if cfg.layers > 4 {
+			return "", invalidConfig("invalid layers option in AztecConfig: %v", cfg.layers)
+		}
+		return symbolCommand(aztecSymbol, 65, 1, cfg.layers), nil
+	default:
+		return "", invalidConfig("invalid mode option in AztecConfig: %v", cfg.mode)
+	}
+}
+
+func (EpsonTMT88VII) SetAztecModuleSizeCommand(cfg *AztecConfig) (string, error) {
+	if cfg.moduleSize < 2 || cfg.moduleSize > 16 {
+		return "", invalidConfig("invalid module size option in AztecConfig: %v", cfg.moduleSize)
+	}
+
+	return symbolCommand(aztecSymbol, 66, cfg.moduleSize), nil
+}
+
+func (EpsonTMT88VII) SetAztecErrorCorrectionCommand(cfg *AztecConfig) (string, error) {
+	if cfg.errorCorrection < 5 || cfg.errorCorrection > 95 {
+		return "", invalidConfig("invalid error correction option in AztecConfig: %v", cfg.errorCorrection)
+	}
+
+	return symbolCommand(aztecSymbol, 67, cfg.errorCorrection), nil
+}
+
+func (EpsonTMT88VII) StoreAztecDataCommand(data string) (string, error) {
+	if len(data) < 1 || len(data) > 3832 {
+		return "", invalidConfig("invalid Aztec data length: %v", len(data))
+	}
+
+	return symbolCommand(aztecSymbol, 80, append([]byte{48}, data...)...), nil
+}
+
+func (EpsonTMT88VII) PrintAztecDataCommand() (string, error) {
+	return symbolCommand(aztecSymbol, 81, 48), nil
+}
@@ -0,0 +1,190 @@
+package escpos
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEpsonTMT88VII_DataMatrixCommands(t *testing.T) {
+	var profile DataMatrix = EpsonTMT88VII{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(*DataMatrixConfig) (string, error)
+		cfg         DataMatrixConfig
+		want        []byte
+	}{
+		{"datamatrix automatic square returns correct value", profile.SelectDataMatrixTypeCommand, DefaultDataMatrixConfig(), []byte{'\x1D', '(', 'k', 5, 0, 54, 66, 0, 0, 0}},
+		{"datamatrix 24x24 square returns correct value", profile.SelectDataMatrixTypeCommand, DefaultDataMatrixConfig().Size(24, 24), []byte{'\x1D', '(', 'k', 5, 0, 54, 66, 0, 24, 24}},
+		{"datamatrix 36x12 rectangle returns correct value", profile.SelectDataMatrixTypeCommand, DefaultDataMatrixConfig().Shape("rectangle").Size(36, 12), []byte{'\x1D', '(', 'k', 5, 0, 54, 66, 1, 12, 36}},
+		{"datamatrix module size 6 returns correct value", profile.SetDataMatrixModuleSizeCommand, DefaultDataMatrixConfig().ModuleSize(6), []byte{'\x1D', '(', 'k', 3, 0, 54, 67, 6}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			gotAsBytes := []byte(got)
+
+			if !bytes.Equal(gotAsBytes, testCase.want) {
+				t.Errorf("command did not return expected bytes: wanted %v, got %v", testCase.want, gotAsBytes)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name        string
+		commandFunc func(*DataMatrixConfig) (string, error)
+		cfg         DataMatrixConfig
+		wantError   string
+	}{
+		{"datamatrix 28x28 square returns error", profile.SelectDataMatrixTypeCommand, DefaultDataMatrixConfig().Size(28, 28), "invalid square size option in DataMatrixConfig: 28x28"},
+		{"datamatrix 24x26 square returns error", profile.SelectDataMatrixTypeCommand, DefaultDataMatrixConfig().Size(24, 26), "invalid square size option in DataMatrixConfig: 24x26"},
+		{"datamatrix 24x8 rectangle returns error", profile.SelectDataMatrixTypeCommand, DefaultDataMatrixConfig().Shape("rectangle").Size(24, 8), "invalid rectangle size option in DataMatrixConfig: 24x8"},
+		{"datamatrix unknown shape returns error", profile.SelectDataMatrixTypeCommand, DefaultDataMatrixConfig().Shape("circle"), "invalid shape option in DataMatrixConfig: circle"},
+		{"datamatrix module size 17 returns error", profile.SetDataMatrixModuleSizeCommand, DefaultDataMatrixConfig().ModuleSize(17), "invalid module size option in DataMatrixConfig: 17"},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if got != "" || err == nil {
+				t.Fatalf("returned command was not nil, expected empty string and error")
+			}
+
+			if err.Error() != testCase.wantError {
+				t.Errorf("command did not return expected error, got %s, wanted %s", err.Error(), testCase.wantError)
+			}
+		})
+	}
+
+	t.Run("datamatrix gs1 store data command starts with FNC1", func(t *testing.T) {
+		cfg := DefaultDataMatrixConfig().Gs1(true)
+		got, err := profile.StoreDataMatrixDataCommand("10AB\x1D211", &cfg)
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := []byte{'\x1D', '(', 'k', 12, 0, 54, 80, 48, '\x1D', '1', '0', 'A', 'B', '\x1D', '2', '1', '1'}
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("StoreDataMatrixDataCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+}
+
+func TestEpsonTMT88VII_AztecCommands(t *testing.T) {
+	var profile Aztec = EpsonTMT88VII{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(*AztecConfig) (string, error)
+		cfg         AztecConfig
+		want        []byte
+	}{
+		{"aztec full range returns correct value", profile.SelectAztecModeCommand, DefaultAztecConfig().Layers(32), []byte{'\x1D', '(', 'k', 4, 0, 53, 65, 0, 32}},
+		{"aztec compact returns correct value", profile.SelectAztecModeCommand, DefaultAztecConfig().Mode("compact").Layers(4), []byte{'\x1D', '(', 'k', 4, 0, 53, 65, 1, 4}},
+		{"aztec module size 2 returns correct value", profile.SetAztecModuleSizeCommand, DefaultAztecConfig().ModuleSize(2), []byte{'\x1D', '(', 'k', 3, 0, 53, 66, 2}},
+		{"aztec error correction 50 returns correct value", profile.SetAztecErrorCorrectionCommand, DefaultAztecConfig().ErrorCorrection(50), []byte{'\x1D', '(', 'k', 3, 0, 53, 67, 50}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			gotAsBytes := []byte(got)
+
+			if !bytes.Equal(gotAsBytes, testCase.want) {
+				t.Errorf("command did not return expected bytes: wanted %v, got %v", testCase.want, gotAsBytes)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name        string
+		commandFunc func(*AztecConfig) (string, error)
+		cfg         AztecConfig
+		wantError   string
+	}{
+		{"aztec compact with 5 layers returns error", profile.SelectAztecModeCommand, DefaultAztecConfig().Mode("compact").Layers(5), "invalid layers option in AztecConfig: 5"},
+		{"aztec unknown mode returns error", profile.SelectAztecModeCommand, DefaultAztecConfig().Mode("rune"), "invalid mode option in AztecConfig: rune"},
+		{"aztec error correction 4 returns error", profile.SetAztecErrorCorrectionCommand, DefaultAztecConfig().ErrorCorrection(4), "invalid error correction option in AztecConfig: 4"},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(&testCase.cfg)
+
+			if got != "" || err == nil {
+				t.Fatalf("returned command was not nil, expected empty string and error")
+			}
+
+			if err.Error() != testCase.wantError {
+				t.Errorf("command did not return expected error, got %s, wanted %s", err.Error(), testCase.wantError)
+			}
+		})
+	}
+}
+
+func TestEpsonTMT88VII_SelectUTF8Command(t *testing.T) {
+	got, err := EpsonTMT88VII{}.SelectUTF8Command("Korean")
+
+	if err != nil {
+		t.Errorf("err was not nil")
+	}
+
+	want := "\x1C(C\x02\x000\x02\x1C(C\x03\x00<\x00\x28"
+
+	if got != want {
+		t.Errorf("SelectUTF8Command did not return expected bytes: wanted %q, got %q", want, got)
+	}
+}
+
+func TestEpsonTMT88VII_FeedAndSpacingCommands(t *testing.T) {
+	profile := EpsonTMT88VII{}
+
+	cases := []struct {
+		name        string
+		commandFunc func(int) (string, error)
+		value       int
+		want        string
+	}{
+		{"feed 24 dots is 48 motion units", profile.FeedDotsCommand, 24, "\x1BJ\x30"},
+		{"feed 200 dots is split", profile.FeedDotsCommand, 200, "\x1BJ\xFF\x1BJ\x91"},
+		{"line spacing 30 dots is 60 motion units", profile.LineSpacingCommand, 30, "\x1B3\x3C"},
+		{"character spacing is unchanged", profile.CharSpacingCommand, 2, "\x1B \x02"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.commandFunc(testCase.value)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("command did not return expected bytes: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+
+	t.Run("line spacing beyond 255 motion units returns error", func(t *testing.T) {
+		_, err := profile.LineSpacingCommand(128)
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("LineSpacingCommand did not return config error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package escpos
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnsupported is matched by errors returned when the profile does
+	// not support the requested command or option.
+	ErrUnsupported = errors.New("unsupported by profile")
+
+	// ErrInvalidConfig is matched by errors returned when a config holds
+	// a value that cannot be turned into a command.
+	ErrInvalidConfig = errors.New("invalid config value")
+
+	// ErrUnencodable is matched by errors returned when text contains a
+	// character that no code table of the profile can print.
+	ErrUnencodable = errors.New("character cannot be encoded")
+
+	// ErrNotBidirectional is returned by methods reading from the
+	// printer on clients created with NewClient, which cannot read.
+	ErrNotBidirectional = errors.New("client cannot read from printer")
+
+	// ErrClosed is returned by methods reading from the printer once the
+	// client has been closed.
+	ErrClosed = errors.New("client is closed")
+
+	// ErrTimeout is returned when the printer does not reply in time.
+	ErrTimeout = errors.New("printer did not reply in time")
+
+	// ErrInvalidStatus is matched by errors returned when the printer
+	// replies to a status query with a byte that is not a status.
+	ErrInvalidStatus = errors.New("invalid status reply")
+)
+
+// UnsupportedError is returned when the profile does not support the
+// requested command or option. It matches ErrUnsupported.
+type UnsupportedError struct {
+	Feature string
+}
+
+func (err *UnsupportedError) Error() string {
+	return fmt.Sprintf("%v is not supported by profile", err.Feature)
+}
+
+func (err *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// ConfigError is returned when a config value is invalid for the
+// profile. It matches ErrInvalidConfig.
+type ConfigError struct {
+	Message string
+}
+
+func (err *ConfigError) Error() string {
+	return err.Message
+}
+
+func (err *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// EncodingError is returned, under the error character fallback, when
+// text contains a character which cannot be encoded in CodeTable. It
+// matches ErrUnencodable.
+type EncodingError struct {
+	Char      rune
+	CodeTable string
+}
+
+func (err *EncodingError) Error() string {
+	return fmt.Sprintf("character %q cannot be encoded in code table %v", err.Char, err.CodeTable)
+}
+
+func (err *EncodingError) Is(target error) bool {
+	return target == ErrUnencodable
+}
+
+// WriteError is returned when the underlying io.Writer fails or accepts
+// fewer bytes than it was given. Written is the number of bytes that
+// reached the writer and Pending holds the remainder of the command, so
+// callers can resend Pending to complete it.
+type WriteError struct {
+	Written int
+	Pending []byte
+	Err     error
+}
+
+func (err *WriteError) Error() string {
+	return fmt.Sprintf("write failed after %v bytes (%v pending): %v", err.Written, len(err.Pending), err.Err)
+}
+
+func (err *WriteError) Unwrap() error {
+	return err.Err
+}
+
+// ReadError is returned when the underlying io.Reader fails while
+// waiting for the printer to reply.
+type ReadError struct {
+	Err error
+}
+
+func (err *ReadError) Error() string {
+	return fmt.Sprintf("read failed: %v", err.Err)
+}
+
+func (err *ReadError) Unwrap() error {
+	return err.Err
+}
+
+// StatusError is returned when the printer replies to a status query
+// with a byte that is not a status. It matches ErrInvalidStatus.
+type StatusError struct {
+	Reply byte
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("invalid status reply: %#02x", err.Reply)
+}
+
+func (err *StatusError) Is(target error) bool {
+	return target == ErrInvalidStatus
+}
+
+func unsupported(feature string) error {
+	return &UnsupportedError{Feature: feature}
+}
+
+func invalidConfig(format string, args ...any) error {
+	return &ConfigError{Message: fmt.Sprintf(format, args...)}
+}
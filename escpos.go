@@ -1,120 +1,1324 @@
 package escpos
 
 import (
-	"fmt"
+	"errors"
+	"image"
 	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/image/font/opentype"
 )
 
 // Client is an ESC/POS client that can be used to interact with an
 // ESC/POS printer such as the Epson TM-T20II.
 type Client struct {
-	writer  io.Writer
-	profile Profile
+	writer    io.Writer
+	replies   *replyReader
+	profile   Profile
+	codeTable string
+	fallback  string
+	charset   string
+	priority  string
+
+	rasterFont *opentype.Font
+	rasterSize float64
 }
 
+// utf8CodeTable is the active code table of a printer which was sent
+// UTF-8 text.
+const utf8CodeTable = "UTF-8"
+
 // NewClient creates an ESC/POS client which takes an io.Writer as
-// the target to write ESC/POS commands to.
-func NewClient(writer io.Writer, profile Profile) Client {
+// the target to write ESC/POS commands to. The printer is initialised
+// before the client is returned.
+func NewClient(writer io.Writer, profile Profile) (Client, error) {
 	client := Client{
-		writer,
-		profile,
+		writer:   writer,
+		profile:  profile,
+		fallback: "replace",
 	}
-	client.Init()
-	return client
+	err := client.Init()
+	return client, err
+}
+
+// NewBidirectionalClient creates an ESC/POS client which writes commands
+// to and reads replies from the io.ReadWriter, such as a network
+// connection or a USB or serial port opened for reading and writing,
+// so the printer's status can be queried. The printer is initialised
+// before the client is returned. Replies are read by a single background
+// reader, which copies of the client share.
+func NewBidirectionalClient(rw io.ReadWriter, profile Profile) (Client, error) {
+	client, err := NewClient(rw, profile)
+	client.replies = newReplyReader(rw, profile)
+	return client, err
 }
 
-func (client *Client) writeRaw(data []byte) {
-	_, err := client.writer.Write(data)
+// NewDetectedClient creates an ESC/POS client for a printer whose
+// profile is not known in advance. The printer is identified with the
+// registry's fallback profile, waiting at most the timeout for each
+// reply, and the client then uses the profile registered for the model
+// name the printer reports. The fallback is kept if there is none, or
+// the printer does not report its model name or replies to none of the
+// queries. The printer is initialised with the chosen profile before
+// the client is returned. Identify returns what the printer reported.
+// A ConfigError is returned if the registry or its fallback is nil.
+func NewDetectedClient(rw io.ReadWriter, registry *ProfileRegistry, timeout time.Duration) (Client, error) {
+	if registry == nil || registry.fallback == nil {
+		return Client{}, invalidConfig("profile registry with a fallback profile is required")
+	}
+
+	client, err := NewBidirectionalClient(rw, registry.fallback)
+	if err != nil {
+		return client, err
+	}
+
+	info, err := client.Identify(timeout)
+	if errors.Is(err, ErrTimeout) {
+		return client, nil
+	}
 	if err != nil {
-		fmt.Printf("error writing data: %v", err)
+		return client, err
 	}
+
+	client.profile = registry.Lookup(info)
+	client.replies.setProfile(client.profile)
+	return client, client.Init()
 }
 
-func (client *Client) writeString(s string) {
-	client.writeRaw([]byte(s))
+// SetCharacterFallback sets how text characters which no code table of
+// the profile can print are handled: replace prints them as '?',
+// transliterate prints a plainer spelling such as e for é or EUR for €
+// where one is known and '?' otherwise, and error makes the write
+// return an EncodingError. The default is replace.
+func (client *Client) SetCharacterFallback(fallback string) error {
+	switch fallback {
+	case "replace", "transliterate", "error":
+		client.fallback = fallback
+		return nil
+	default:
+		return invalidConfig("invalid character fallback: %v", fallback)
+	}
 }
 
-// Init clears the data in the print buffer and resets the printer
-// modes to the modes that were in effect when the power was turned on.
-func (client *Client) Init() {
-	command, err := client.profile.InitCommand()
+// SetInternationalCharacterSet selects the international character set
+// the printer prints the ASCII characters #, $, @, [, \, ], ^, `, {, |,
+// } and ~ in, so the UK set prints £ in place of #. Text written
+// afterwards is transcoded with the set, so £ needs no code table
+// switch and the characters it replaces are handled by the character
+// fallback. The set is selected again whenever the printer is
+// initialised.
+func (client *Client) SetInternationalCharacterSet(set string) error {
+	profileSet, ok := client.profile.(InternationalCharacterSet)
+	if !ok {
+		return unsupported("international character set")
+	}
+	if _, ok := internationalCharacterSets[set]; !ok {
+		return invalidConfig("unknown international character set: %v", set)
+	}
+
+	var buf commandBuffer
+	buf.add(profileSet.SelectInternationalCharacterSetCommand(set))
+	if err := client.writeCommands(&buf); err != nil {
+		return err
+	}
+	client.charset = set
+	return nil
+}
+
+// SetFontPriority sets the font printers accepting UTF-8 text give
+// priority to for characters which several fonts have glyphs for, so
+// Japanese prints Han characters with Japanese glyphs and Simplified
+// Chinese with Chinese glyphs. Supported values usually include ANK,
+// Japanese, Simplified Chinese, Traditional Chinese and Korean.
+func (client *Client) SetFontPriority(font string) error {
+	profileUTF8, ok := client.profile.(UTF8)
+	if !ok {
+		return unsupported("UTF-8")
+	}
+	if _, err := profileUTF8.SelectUTF8Command(font); err != nil {
+		return err
+	}
+
+	client.priority = font
+	if client.codeTable == utf8CodeTable {
+		client.codeTable = ""
+	}
+	return nil
+}
+
+// SetRasterFont sets the TrueType or OpenType font, and its size in
+// dots, which lines of text are rendered in when the printer cannot
+// print them as text. Lines using scripts which need shaping or
+// right-to-left layout, such as Arabic, Hebrew and Thai, and lines with
+// characters no code table of the profile covers, such as emoji, are
+// then rendered and printed as raster images. Right-to-left text is
+// reordered and Arabic letters joined before rendering, so the font
+// must have glyphs for the Arabic presentation forms.
+func (client *Client) SetRasterFont(data []byte, size float64) error {
+	if size <= 0 || math.IsNaN(size) || math.IsInf(size, 0) {
+		return invalidConfig("invalid raster font size: %v", size)
+	}
+
+	rasterFont, err := opentype.Parse(data)
+	if err != nil {
+		return invalidConfig("invalid raster font: %v", err)
+	}
+
+	client.rasterFont = rasterFont
+	client.rasterSize = size
+	return nil
+}
+
+// commandBuffer collects the output of profile command builders so a
+// command sequence is only written once every part of it was built.
+type commandBuffer struct {
+	strings.Builder
+	err error
+}
+
+// add appends the command unless this or an earlier builder failed.
+func (buf *commandBuffer) add(command string, err error) {
+	if buf.err != nil {
+		return
+	}
 	if err != nil {
-		fmt.Printf("error getting init command: %v\n", err)
+		buf.err = err
 		return
 	}
-	client.writeString(command)
+	buf.WriteString(command)
+}
+
+// result returns the collected commands, or the first error seen.
+func (buf *commandBuffer) result() (string, error) {
+	if buf.err != nil {
+		return "", buf.err
+	}
+	return buf.String(), nil
+}
+
+func (client *Client) writeRaw(data []byte) error {
+	n, err := client.writer.Write(data)
+	n = min(max(n, 0), len(data))
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		return &WriteError{Written: n, Pending: data[n:], Err: err}
+	}
+	return nil
+}
+
+func (client *Client) writeString(s string) error {
+	return client.writeRaw([]byte(s))
+}
+
+// writeCommands writes the commands collected in the buffer, or returns
+// the buffer's error without writing anything.
+func (client *Client) writeCommands(buf *commandBuffer) error {
+	commands, err := buf.result()
+	if err != nil {
+		return err
+	}
+	return client.writeString(commands)
+}
+
+// writeText writes the commands in the buffer, which leave the named
+// code table active. The active table becomes unknown if the write
+// fails part way.
+func (client *Client) writeText(buf *commandBuffer, table string) error {
+	err := client.writeCommands(buf)
+	var writeErr *WriteError
+	switch {
+	case err == nil:
+		client.codeTable = table
+	case errors.As(err, &writeErr):
+		client.codeTable = ""
+	}
+	return err
+}
+
+// selectUTF8 returns the command selecting UTF-8 text, and whether the
+// printer accepts UTF-8 text at all.
+func (client *Client) selectUTF8() (string, bool, error) {
+	profileUTF8, ok := client.profile.(UTF8)
+	if !ok {
+		return "", false, nil
+	}
+
+	selectUTF8, err := profileUTF8.SelectUTF8Command(client.priority)
+	if errors.Is(err, ErrUnsupported) {
+		return "", false, nil
+	}
+	return selectUTF8, err == nil, err
+}
+
+// textTables returns the names of the code tables and multi-byte
+// encodings of the profile, followed by the number of code tables, and
+// the tables themselves with the international character set applied.
+// The multi-byte encodings follow the code tables, so a code table is
+// preferred for text both can print.
+func (client *Client) textTables() ([]string, int, []codeTable, error) {
+	var names []string
+	if profileTables, ok := client.profile.(CodeTables); ok {
+		names = profileTables.CodeTables()
+	}
+	singleByte := len(names)
+	if profileMultiByte, ok := client.profile.(MultiByteEncodings); ok {
+		names = append(names, profileMultiByte.MultiByteEncodings()...)
+	}
+
+	tables := make([]codeTable, len(names))
+	for i, name := range names {
+		known := codeTables
+		if i >= singleByte {
+			known = multiByteTables
+		}
+		table, ok := known[name]
+		if !ok {
+			return nil, 0, nil, invalidConfig("unknown code table in profile: %v", name)
+		}
+		tables[i] = table
+		if client.charset != "" {
+			tables[i] = internationalTable{table, []rune(internationalCharacterSets[client.charset])}
+		}
+	}
+	return names, singleByte, tables, nil
+}
+
+// encodeText transcodes UTF-8 text for the printer, starting from the
+// active code table. Text which is all ASCII while no international
+// character set is selected, or for profiles without CodeTables or
+// MultiByteEncodings, is returned unchanged. Printers accepting UTF-8
+// text are sent it unchanged, after selecting UTF-8 if it is not
+// active. Otherwise each run of characters is encoded in a code table
+// or multi-byte encoding of the profile which can print it, with the
+// international character set applied, switching with as few commands
+// as possible and restoring the active table afterwards. The table
+// active afterwards is returned with the encoded text.
+func (client *Client) encodeText(text string, active string) (string, string, error) {
+	if isASCII(text) && client.charset == "" {
+		return text, active, nil
+	}
+
+	selectUTF8, native, err := client.selectUTF8()
+	switch {
+	case err != nil:
+		return "", "", err
+	case native && active == utf8CodeTable:
+		return text, utf8CodeTable, nil
+	case native:
+		return selectUTF8 + text, utf8CodeTable, nil
+	}
+
+	profileTables, _ := client.profile.(CodeTables)
+	profileMultiByte, _ := client.profile.(MultiByteEncodings)
+	if profileTables == nil && profileMultiByte == nil {
+		return text, active, nil
+	}
+
+	names, singleByte, tables, err := client.textTables()
+	if err != nil {
+		return "", "", err
+	}
+	if len(names) == 0 {
+		return "", "", unsupported("code tables")
+	}
+
+	start := -1
+	for i, name := range names {
+		if name == active {
+			start = i
+		}
+	}
+
+	runes := []rune(text)
+	plan := planCodeTables(runes, tables, start)
+
+	// selected is the code table last selected, which the printer
+	// returns to when it leaves multi-byte character mode.
+	var encoded commandBuffer
+	current, selected := start, start
+	if start >= singleByte {
+		selected = -1
+	}
+	switchTo := func(table int) {
+		if current >= singleByte {
+			encoded.add(profileMultiByte.DisableMultiByteCommand(names[current]))
+		}
+		if table >= singleByte {
+			encoded.add(profileMultiByte.EnableMultiByteCommand(names[table]))
+		} else if table != selected {
+			encoded.add(profileTables.SelectCodeTableCommand(names[table]))
+			selected = table
+		}
+		current = table
+	}
+
+	for i, r := range runes {
+		if plan[i] != current {
+			switchTo(plan[i])
+		}
+
+		// Before any table is selected only characters which every
+		// table encodes alike, and those which no table can encode,
+		// are printed, so the most preferred table stands in.
+		table := max(current, 0)
+		encoded.add("", encodeRune(&encoded.Builder, r, names[table], tables[table], client.fallback))
+	}
+
+	if start >= 0 && current != start {
+		switchTo(start)
+	}
+
+	result, err := encoded.result()
+	if err != nil {
+		return "", "", err
+	}
+	if current < 0 {
+		return result, "", nil
+	}
+	return result, names[current], nil
+}
+
+// Init clears the data in the print buffer and resets the printer
+// modes to the modes that were in effect when the power was turned on.
+// This includes the code table, so the next non-ASCII text selects one
+// again. An international character set chosen with
+// SetInternationalCharacterSet is selected again afterwards.
+func (client *Client) Init() error {
+	var buf commandBuffer
+	buf.add(client.profile.InitCommand())
+	if client.charset != "" {
+		buf.add(client.profile.(InternationalCharacterSet).SelectInternationalCharacterSetCommand(client.charset))
+	}
+	return client.writeText(&buf, "")
 }
 
 // WriteLine writes the given string followed by a newline to the
-// ESC/POS target.
-func (client *Client) WriteLine(line string) {
-	client.writeString(line)
-	client.writeString("\n")
+// ESC/POS target. The string is transcoded from UTF-8 into a code
+// table of the profile, or rendered as raster text when it cannot be
+// printed as text and a raster font is set.
+func (client *Client) WriteLine(line string) error {
+	var buf commandBuffer
+	fmtCfg := DefaultFormatConfig()
+	table := client.addText(&buf, line+"\n", &fmtCfg)
+	return client.writeText(&buf, table)
+}
+
+// Write configures the client using the given FormatConfig then writes
+// the given string to the ESC/POS target. The format is reset to the
+// default afterwards. The string is transcoded from UTF-8 into a code
+// table of the profile, and lines which cannot be printed as text are
+// rendered as raster text when a raster font is set. Nothing is written
+// if any format command fails to build, the string cannot be encoded,
+// or the string is printed upside-down without ending its line.
+func (client *Client) Write(s string, fmtCfg FormatConfig) error {
+	if fmtCfg.upsideDown && !strings.HasSuffix(s, "\n") {
+		return invalidConfig("upside-down text must end with a newline")
+	}
+
+	var buf commandBuffer
+	buf.add(fmtCfg.commands(client.profile))
+	table := client.addText(&buf, s, &fmtCfg)
+	buf.add(fmtCfg.resetCommands(client.profile))
+	return client.writeText(&buf, table)
+}
+
+// addText adds the commands printing the text to the buffer, returning
+// the code table active afterwards. Runs of lines the printer can print
+// are transcoded together, and each other line is rendered in the raster
+// font and printed as a raster image, which ends the line.
+func (client *Client) addText(buf *commandBuffer, s string, fmtCfg *FormatConfig) string {
+	table := client.codeTable
+	pending := ""
+	flush := func() {
+		if pending != "" {
+			text, next, err := client.encodeText(pending, table)
+			buf.add(text, err)
+			table, pending = next, ""
+		}
+	}
+
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if client.rasterFont == nil || !client.needsRasterText(line) {
+			pending += line
+			continue
+		}
+
+		flush()
+		client.addRasterText(buf, strings.TrimSuffix(line, "\n"), fmtCfg)
+	}
+
+	flush()
+	return table
 }
 
-// Write configures the client using the given Configurer then writes
-// the given string to the ESC/POS target.
-func (client *Client) Write(s string, fmtCfg FormatConfig) {
-	fmtCfg.apply(client, client.profile)
-	client.writeString(s)
-	DefaultFormatConfig().apply(client, client.profile)
+// needsRasterText reports whether the line uses a script which needs
+// shaping or right-to-left layout, or characters which no code table or
+// encoding of the printer can print.
+func (client *Client) needsRasterText(line string) bool {
+	for _, r := range line {
+		if unicode.In(r, complexScripts...) {
+			return true
+		}
+	}
+	if isASCII(line) {
+		return false
+	}
+
+	// Printers accepting UTF-8 text lack glyphs outside the basic
+	// multilingual plane, such as emoji.
+	if _, native, _ := client.selectUTF8(); native {
+		for _, r := range line {
+			if r > 0xFFFF {
+				return true
+			}
+		}
+		return false
+	}
+
+	_, _, tables, err := client.textTables()
+	if err != nil {
+		return false
+	}
+	for _, r := range line {
+		encodable := r < utf8.RuneSelf
+		for _, table := range tables {
+			_, ok := table.EncodeRune(r)
+			encodable = encodable || ok
+		}
+		if !encodable {
+			return true
+		}
+	}
+	return false
 }
 
-// Cut writes a command which selects the cut mode and cuts the paper.
-func (client *Client) Cut() {
-	command, err := client.profile.CutCommand()
+// addRasterText adds the commands printing the line as raster text to
+// the buffer. The line is scaled down to the printable width when it is
+// too wide.
+func (client *Client) addRasterText(buf *commandBuffer, line string, fmtCfg *FormatConfig) {
+	raster, ok := client.profile.(RasterImage)
+	if !ok {
+		buf.add("", unsupported("raster image"))
+		return
+	}
+
+	gray, err := renderText(line, client.rasterFont, client.rasterSize, fmtCfg)
 	if err != nil {
-		fmt.Printf("error getting cut command: %v\n", err)
+		buf.add("", err)
 		return
 	}
-	client.writeString(command)
+
+	cfg := DefaultImageConfig()
+	if area, ok := client.profile.(PrintArea); ok {
+		cfg.width = min(gray.Bounds().Dx(), area.PrintableWidth())
+	}
+
+	bitmap, err := ConvertImage(gray, cfg)
+	if err != nil {
+		buf.add("", err)
+		return
+	}
+	addBitmapCommands(buf, raster, bitmap)
+}
+
+// Cut feeds the paper until the last printed line has passed the cutter
+// and cuts it, using the default CutConfig.
+func (client *Client) Cut() error {
+	return client.CutWithConfig(DefaultCutConfig())
+}
+
+// CutWithConfig cuts the paper, using the given CutConfig for options
+// such as a partial cut. For profiles implementing CutterPosition whose
+// cut command does not feed to the cutter itself, the paper is fed by
+// the distance to the cutter before a cut which feeds. Nothing is
+// written if any command fails to build.
+func (client *Client) CutWithConfig(cfg CutConfig) error {
+	var buf commandBuffer
+	if position, ok := client.profile.(CutterPosition); ok && cfg.feed && !position.FeedsToCutter() {
+		feed, ok := client.profile.(Feed)
+		if !ok {
+			return unsupported("paper feed")
+		}
+		buf.add(feed.FeedDotsCommand(position.CutterDistance()))
+		cfg.feed = false
+	}
+	buf.add(client.profile.CutCommand(&cfg))
+	return client.writeCommands(&buf)
+}
+
+// Feed prints any buffered data and feeds the paper by the given number
+// of lines, at the current line spacing.
+func (client *Client) Feed(lines int) error {
+	feed, ok := client.profile.(Feed)
+	if !ok {
+		return unsupported("paper feed")
+	}
+
+	var buf commandBuffer
+	buf.add(feed.FeedLinesCommand(lines))
+	return client.writeCommands(&buf)
+}
+
+// FeedDots prints any buffered data and feeds the paper by the given
+// number of dots.
+func (client *Client) FeedDots(dots int) error {
+	feed, ok := client.profile.(Feed)
+	if !ok {
+		return unsupported("paper feed")
+	}
+
+	var buf commandBuffer
+	buf.add(feed.FeedDotsCommand(dots))
+	return client.writeCommands(&buf)
+}
+
+// ReverseFeed prints any buffered data and feeds the paper back by the
+// given number of lines, on printers which can feed backwards.
+func (client *Client) ReverseFeed(lines int) error {
+	feed, ok := client.profile.(ReverseFeed)
+	if !ok {
+		return unsupported("reverse feed")
+	}
+
+	var buf commandBuffer
+	buf.add(feed.ReverseFeedLinesCommand(lines))
+	return client.writeCommands(&buf)
+}
+
+// SetLineSpacing sets the spacing between the tops of consecutive lines
+// of text to the given number of dots. The spacing stays in effect until
+// it is changed, ResetLineSpacing is called or the printer is
+// initialised.
+func (client *Client) SetLineSpacing(dots int) error {
+	spacing, ok := client.profile.(Spacing)
+	if !ok {
+		return unsupported("spacing")
+	}
+
+	var buf commandBuffer
+	buf.add(spacing.LineSpacingCommand(dots))
+	return client.writeCommands(&buf)
+}
+
+// ResetLineSpacing restores the printer's default line spacing.
+func (client *Client) ResetLineSpacing() error {
+	spacing, ok := client.profile.(Spacing)
+	if !ok {
+		return unsupported("spacing")
+	}
+
+	var buf commandBuffer
+	buf.add(spacing.DefaultLineSpacingCommand())
+	return client.writeCommands(&buf)
+}
+
+// SetCharSpacing sets the space printed to the right of each character
+// to the given number of dots, 0 being the default. The spacing stays in
+// effect until it is changed or the printer is initialised.
+func (client *Client) SetCharSpacing(dots int) error {
+	spacing, ok := client.profile.(Spacing)
+	if !ok {
+		return unsupported("spacing")
+	}
+
+	var buf commandBuffer
+	buf.add(spacing.CharSpacingCommand(dots))
+	return client.writeCommands(&buf)
+}
+
+// SetLeftMargin sets the left margin of the print area, which lines
+// are printed and justified from. It takes effect from the start of
+// the next line and stays in effect until it is changed or the printer
+// is initialised.
+func (client *Client) SetLeftMargin(margin Length) error {
+	return client.writePosition(margin, func(positioning Positioning, dots int) (string, error) {
+		return positioning.LeftMarginCommand(dots)
+	})
+}
+
+// SetPrintAreaWidth sets the width of the print area to the right of
+// the left margin. It takes effect from the start of the next line and
+// stays in effect until it is changed or the printer is initialised.
+func (client *Client) SetPrintAreaWidth(width Length) error {
+	return client.writePosition(width, func(positioning Positioning, dots int) (string, error) {
+		return positioning.PrintAreaWidthCommand(dots)
+	})
+}
+
+// MoveTo moves the print position on the current line to the given
+// distance from the left margin, so text written next starts there.
+func (client *Client) MoveTo(x Length) error {
+	return client.writePosition(x, func(positioning Positioning, dots int) (string, error) {
+		return positioning.AbsolutePositionCommand(dots)
+	})
+}
+
+// MoveBy moves the print position on the current line by the given
+// distance, to the left when it is negative.
+func (client *Client) MoveBy(dx Length) error {
+	return client.writePosition(dx, func(positioning Positioning, dots int) (string, error) {
+		return positioning.RelativePositionCommand(dots)
+	})
+}
+
+// writePosition converts the length to dots and writes the positioning
+// command built for it.
+func (client *Client) writePosition(length Length, command func(Positioning, int) (string, error)) error {
+	positioning, ok := client.profile.(Positioning)
+	if !ok {
+		return unsupported("positioning")
+	}
+	dots, err := length.dots(client.profile)
+	if err != nil {
+		return err
+	}
+
+	var buf commandBuffer
+	buf.add(command(positioning, dots))
+	return client.writeCommands(&buf)
 }
 
 // End signifies the printing has completed and subsequent data is
 // considered separate.
-func (client *Client) End() {
-	command, err := client.profile.EndCommand()
-	if err != nil {
-		fmt.Printf("error getting end command: %v\n", err)
-		return
+func (client *Client) End() error {
+	var buf commandBuffer
+	buf.add(client.profile.EndCommand())
+	return client.writeCommands(&buf)
+}
+
+// Commit signifies the printing has completed, like End, then waits at
+// most the timeout for the printer to confirm it has printed everything
+// written before. Each commit sends the printer a new process ID, which
+// it responds with once it reaches it, so a response to an earlier
+// commit which timed out is not mistaken for confirmation. A printer
+// which is offline, for example because it ran out of paper, does not
+// respond until it is back online, and ErrTimeout is returned. Status
+// can then be used to find out why.
+func (client *Client) Commit(timeout time.Duration) error {
+	if client.replies == nil {
+		return ErrNotBidirectional
+	}
+	pid, ok := client.profile.(ProcessID)
+	if !ok {
+		return unsupported("process ID")
+	}
+
+	id := client.replies.newProcessID()
+
+	var buf commandBuffer
+	buf.add(client.profile.EndCommand())
+	buf.add(pid.ProcessIDCommand(id))
+
+	replies := client.replies
+	if err := client.writeCommands(&buf); err != nil {
+		return err
+	}
+	return replies.awaitProcessID(id, timeout)
+}
+
+// OpenDrawer opens the cash drawer wired to the given pin of the
+// printer's drawer kick-out connector, 2 or 5, by switching the pin on
+// for onTime and then off for offTime. Most drawers open with pin 2 and
+// an on time of 100 ms. Nothing is printed, so the drawer can be opened
+// without a sale. The pulse is sent once the printer has processed the
+// data written before it.
+func (client *Client) OpenDrawer(pin int, onTime time.Duration, offTime time.Duration) error {
+	drawer, ok := client.profile.(CashDrawer)
+	if !ok {
+		return unsupported("cash drawer")
+	}
+
+	var buf commandBuffer
+	buf.add(drawer.DrawerPulseCommand(pin, onTime, offTime))
+	return client.writeCommands(&buf)
+}
+
+// OpenDrawerNow opens the cash drawer like OpenDrawer, but straight
+// away, even when the printer is offline or busy printing. The pin is
+// switched on and then off for the pulse time each.
+func (client *Client) OpenDrawerNow(pin int, pulse time.Duration) error {
+	drawer, ok := client.profile.(CashDrawer)
+	if !ok {
+		return unsupported("cash drawer")
 	}
-	client.writeString(command)
+
+	var buf commandBuffer
+	buf.add(drawer.RealTimeDrawerPulseCommand(pin, pulse))
+	return client.writeCommands(&buf)
 }
 
 // WriteQrCode writes the given data as a QR code to the printer,
 // using the given QrCodeConfig for options such as size and model.
-func (client *Client) WriteQrCode(data string, cfg QrCodeConfig) {
-	DefaultFormatConfig().Justify(cfg.justification).apply(client, client.profile)
+// Nothing is written if any QR code command fails to build.
+func (client *Client) WriteQrCode(data string, cfg QrCodeConfig) error {
+	return client.WriteQrCodeBytes([]byte(data), cfg)
+}
 
-	c, err := client.profile.SelectQrCodeModelCommand(&cfg)
-	if err != nil {
-		fmt.Printf("error getting select QR code model command: %v\n", err)
+// WriteQrCodeBytes writes the given binary data as a QR code to the
+// printer, using the given QrCodeConfig for options such as size and
+// model. The data is checked with PlanQrCode, and against the printable
+// width of the profile, before anything is written. With structured
+// append enabled, data which does not fit a single symbol is split
+// across up to 16 linked symbols instead.
+func (client *Client) WriteQrCodeBytes(data []byte, cfg QrCodeConfig) error {
+	maxWidth := math.MaxInt
+	if area, ok := client.profile.(PrintArea); ok {
+		maxWidth = area.PrintableWidth()
 	}
-	client.writeString(c)
 
-	c, err = client.profile.SetQrCodeSizeCommand(&cfg)
+	info, err := PlanQrCode(data, cfg)
+	if cfg.structuredAppend && (err != nil || info.Width > maxWidth) {
+		return client.writeStructuredQrCode(data, cfg, maxWidth)
+	}
 	if err != nil {
-		fmt.Printf("error getting set QR code size command: %v\n", err)
+		return err
 	}
-	client.writeString(c)
+	if info.Width > maxWidth {
+		return invalidConfig("QR code version %v is too wide for the print area: %v dots > %v dots (max)", info.Version, info.Width, maxWidth)
+	}
+
+	if !client.nativeQrCode() {
+		bitmap, err := renderQrCode(data, &cfg, nil)
+		if err != nil {
+			return err
+		}
+		return client.writeRenderedSymbol("QR code", bitmap, cfg.justification)
+	}
+
+	var buf commandBuffer
+	buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+	client.addQrCodeCommands(&buf, data, &cfg)
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
 
-	c, err = client.profile.SelectQrCodeErrorCorrectionLevelCommand(&cfg)
+// writeStructuredQrCode writes the data as linked structured append
+// symbols, one after another or side by side in page mode.
+func (client *Client) writeStructuredQrCode(data []byte, cfg QrCodeConfig, maxWidth int) error {
+	parts, info, err := splitQrCode(data, cfg, maxWidth)
 	if err != nil {
-		fmt.Printf("error getting select QR code error correction level command: %v\n", err)
+		return err
 	}
-	client.writeString(c)
+	parity := qrParity(data)
 
-	c, err = client.profile.StoreQrCodeDataCommand(data)
+	// Symbols are laid out in rows on a grid of cells the size of the
+	// largest symbol, separated by a quiet zone of 4 modules.
+	gap := 4 * int(cfg.size)
+	cell := info.Width + gap
+	columns := 1
+	if cfg.sideBySide {
+		columns = min(max((maxWidth+gap)/cell, 1), len(parts))
+	}
+
+	if !client.nativeQrCode() {
+		symbols := make([]*Bitmap, len(parts))
+		for i, part := range parts {
+			symbols[i], err = renderQrCode(part, &cfg, &qrStructuredAppend{index: i, total: len(parts), parity: parity})
+			if err != nil {
+				return err
+			}
+		}
+		return client.writeRenderedSymbol("QR code", tileBitmaps(symbols, columns, gap), cfg.justification)
+	}
+
+	structuredAppend, ok := client.profile.(QrCodeStructuredAppend)
+	if !ok {
+		return unsupported("QR code structured append")
+	}
+
+	var buf commandBuffer
+	if !cfg.sideBySide {
+		buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+		for i, part := range parts {
+			buf.add(structuredAppend.QrCodeStructuredAppendCommand(i, len(parts), parity))
+			client.addQrCodeCommands(&buf, part, &cfg)
+		}
+		buf.add(DefaultFormatConfig().commands(client.profile))
+		return client.writeCommands(&buf)
+	}
+
+	pageMode, ok := client.profile.(PageMode)
+	if !ok {
+		return unsupported("page mode")
+	}
+	if _, ok := client.profile.(PrintArea); !ok {
+		return unsupported("print area")
+	}
+
+	rows := (len(parts) + columns - 1) / columns
+
+	offset := 0
+	switch cfg.justification {
+	case "center":
+		offset = (maxWidth - columns*cell + gap) / 2
+	case "right":
+		offset = maxWidth - columns*cell + gap
+	}
+
+	buf.add(pageMode.EnterPageModeCommand())
+	buf.add(pageMode.SetPageAreaCommand(0, 0, maxWidth, rows*cell-gap))
+	for i, part := range parts {
+		buf.add(pageMode.SetPagePositionCommand(offset+(i%columns)*cell, (i/columns)*cell+info.Width))
+		buf.add(structuredAppend.QrCodeStructuredAppendCommand(i, len(parts), parity))
+		client.addQrCodeCommands(&buf, part, &cfg)
+	}
+	buf.add(pageMode.PrintPageCommand())
+	return client.writeCommands(&buf)
+}
+
+// nativeQrCode reports whether the profile prints QR codes itself,
+// rather than returning an UnsupportedError from its QR code commands.
+func (client *Client) nativeQrCode() bool {
+	_, err := client.profile.PrintQrCodeDataCommand()
+	return !errors.Is(err, ErrUnsupported)
+}
+
+// addQrCodeCommands adds the commands to store and print a single QR
+// code symbol to the buffer.
+func (client *Client) addQrCodeCommands(buf *commandBuffer, data []byte, cfg *QrCodeConfig) {
+	buf.add(client.profile.SelectQrCodeModelCommand(cfg))
+	buf.add(client.profile.SetQrCodeSizeCommand(cfg))
+	buf.add(client.profile.SelectQrCodeErrorCorrectionLevelCommand(cfg))
+	buf.add(client.profile.StoreQrCodeDataCommand(string(data)))
+	buf.add(client.profile.PrintQrCodeDataCommand())
+}
+
+// WriteImage writes the given image to the printer as a raster bit
+// image, using the given ImageConfig for options such as width and
+// justification. The image is converted to 1-bit as by ConvertImage
+// and split into bands when it is taller than the profile can print in
+// a single command.
+func (client *Client) WriteImage(img image.Image, cfg ImageConfig) error {
+	raster, ok := client.profile.(RasterImage)
+	if !ok {
+		return unsupported("raster image")
+	}
+
+	if cfg.width == 0 {
+		cfg.width = img.Bounds().Dx()
+	}
+	if area, ok := client.profile.(PrintArea); ok {
+		cfg.width = min(cfg.width, area.PrintableWidth())
+	}
+
+	bitmap, err := ConvertImage(img, cfg)
 	if err != nil {
-		fmt.Printf("error getting store QR code data command: %v\n", err)
+		return err
+	}
+
+	return client.writeBitmap(raster, bitmap, cfg.justification)
+}
+
+// writeBitmap writes the bitmap in bands no taller than the profile
+// supports, justified as given.
+func (client *Client) writeBitmap(raster RasterImage, bitmap *Bitmap, justification string) error {
+	var buf commandBuffer
+	buf.add(DefaultFormatConfig().Justify(justification).commands(client.profile))
+	addBitmapCommands(&buf, raster, bitmap)
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
+
+// addBitmapCommands adds the commands to print the bitmap in bands no
+// taller than the profile supports to the buffer.
+func addBitmapCommands(buf *commandBuffer, raster RasterImage, bitmap *Bitmap) {
+	bandHeight := raster.MaxRasterBandHeight()
+	if bandHeight < 1 {
+		buf.add("", invalidConfig("invalid raster band height in profile: %v", bandHeight))
+		return
+	}
+
+	for y := 0; y < bitmap.Height; y += bandHeight {
+		buf.add(raster.RasterImageCommand(bitmap.band(y, min(y+bandHeight, bitmap.Height))))
+	}
+}
+
+// writeRenderedSymbol writes a symbol which the client has rendered
+// itself, for profiles without native support for it, as a raster
+// image.
+func (client *Client) writeRenderedSymbol(feature string, bitmap *Bitmap, justification string) error {
+	raster, ok := client.profile.(RasterImage)
+	if !ok {
+		return unsupported(feature)
+	}
+	return client.writeBitmap(raster, bitmap, justification)
+}
+
+// WriteBarcode writes the given data as a 1D barcode to the printer,
+// using the given BarcodeConfig for options such as symbology and
+// height. The data is validated for the symbology, and where the
+// symbol width is known checked against the printable width, before
+// anything is written. Profiles without native support for the
+// symbology have the barcode rendered by the client and printed as a
+// raster image.
+func (client *Client) WriteBarcode(data string, cfg BarcodeConfig) error {
+	if err := validateBarcodeData(data, &cfg); err != nil {
+		return err
+	}
+
+	barcode, native := client.profile.(Barcode)
+	if native {
+		_, err := barcode.PrintBarcodeCommand(data, &cfg)
+		native = !errors.Is(err, ErrUnsupported)
+	}
+	if !native {
+		return client.writeRenderedBarcode(data, cfg)
 	}
-	client.writeString(c)
 
-	c, err = client.profile.PrintQrCodeDataCommand()
+	if area, ok := client.profile.(PrintArea); ok {
+		modules, known := barcodeModules(data, &cfg)
+		if width := modules * int(cfg.width); known && width > area.PrintableWidth() {
+			return invalidConfig("%v barcode is too wide for the print area: %v dots > %v dots (max)", cfg.symbology, width, area.PrintableWidth())
+		}
+	}
+
+	var buf commandBuffer
+	buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+	buf.add(barcode.SetBarcodeWidthCommand(&cfg))
+	buf.add(barcode.SetBarcodeHeightCommand(&cfg))
+	buf.add(barcode.SelectHriPositionCommand(&cfg))
+	buf.add(barcode.SelectHriFontCommand(&cfg))
+	buf.add(barcode.PrintBarcodeCommand(data, &cfg))
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
+
+// writeRenderedBarcode writes a barcode rendered by the client as a
+// raster image, with its human readable interpretation printed as text
+// in the HRI font.
+func (client *Client) writeRenderedBarcode(data string, cfg BarcodeConfig) error {
+	raster, ok := client.profile.(RasterImage)
+	if !ok {
+		return unsupported("barcode")
+	}
+
+	bitmap, hri, err := renderBarcode(data, &cfg)
 	if err != nil {
-		fmt.Printf("error getting print QR code data command: %v\n", err)
+		return err
+	}
+
+	if area, ok := client.profile.(PrintArea); ok && bitmap.Width > area.PrintableWidth() {
+		return invalidConfig("%v barcode is too wide for the print area: %v dots > %v dots (max)", cfg.symbology, bitmap.Width, area.PrintableWidth())
+	}
+
+	var above, below bool
+	switch cfg.hriPosition {
+	case "none":
+	case "above":
+		above = true
+	case "below":
+		below = true
+	case "both":
+		above, below = true, true
+	default:
+		return invalidConfig("invalid HRI position option in BarcodeConfig: %v", cfg.hriPosition)
+	}
+
+	hriFormat := DefaultFormatConfig().Font(cfg.hriFont).Justify(cfg.justification)
+
+	var buf commandBuffer
+	if above {
+		buf.add(hriFormat.commands(client.profile))
+		buf.add(hri+"\n", nil)
+	}
+	buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+	addBitmapCommands(&buf, raster, bitmap)
+	if below {
+		buf.add(hriFormat.commands(client.profile))
+		buf.add(hri+"\n", nil)
+	}
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
+
+// WritePdf417 writes the given data as a PDF417 symbol to the printer,
+// using the given Pdf417Config for options such as columns and error
+// correction. Nothing is written if any PDF417 command fails to build.
+func (client *Client) WritePdf417(data string, cfg Pdf417Config) error {
+	pdf417, ok := client.profile.(Pdf417)
+	if !ok {
+		return unsupported("PDF417")
+	}
+
+	var buf commandBuffer
+	buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+	buf.add(pdf417.SetPdf417ColumnsCommand(&cfg))
+	buf.add(pdf417.SetPdf417RowsCommand(&cfg))
+	buf.add(pdf417.SetPdf417ModuleWidthCommand(&cfg))
+	buf.add(pdf417.SetPdf417RowHeightCommand(&cfg))
+	buf.add(pdf417.SelectPdf417ErrorCorrectionLevelCommand(&cfg))
+	buf.add(pdf417.SelectPdf417OptionsCommand(&cfg))
+	buf.add(pdf417.StorePdf417DataCommand(data))
+	buf.add(pdf417.PrintPdf417DataCommand())
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
+
+// WriteMaxiCode writes the given data as a MaxiCode symbol to the
+// printer, using the given MaxiCodeConfig for options such as mode.
+func (client *Client) WriteMaxiCode(data string, cfg MaxiCodeConfig) error {
+	maxiCode, ok := client.profile.(MaxiCode)
+	if !ok {
+		return unsupported("MaxiCode")
+	}
+
+	var buf commandBuffer
+	buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+	buf.add(maxiCode.SelectMaxiCodeModeCommand(&cfg))
+	buf.add(maxiCode.StoreMaxiCodeDataCommand(data))
+	buf.add(maxiCode.PrintMaxiCodeDataCommand())
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
+
+// WriteDataMatrix writes the given data as a DataMatrix (ECC200) symbol
+// to the printer, using the given DataMatrixConfig for options such as
+// size and GS1 mode. In GS1 mode the data is given in bracketed
+// Application Identifier syntax and validated before anything is
+// written.
+func (client *Client) WriteDataMatrix(data string, cfg DataMatrixConfig) error {
+	dataMatrix, ok := client.profile.(DataMatrix)
+	if !ok {
+		return unsupported("DataMatrix")
+	}
+
+	if cfg.gs1 {
+		elementString, err := gs1ElementString(data)
+		if err != nil {
+			return err
+		}
+		data = elementString
+	}
+
+	var buf commandBuffer
+	buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+	buf.add(dataMatrix.SelectDataMatrixTypeCommand(&cfg))
+	buf.add(dataMatrix.SetDataMatrixModuleSizeCommand(&cfg))
+	buf.add(dataMatrix.StoreDataMatrixDataCommand(data, &cfg))
+	buf.add(dataMatrix.PrintDataMatrixDataCommand())
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
+
+// WriteAztec writes the given data as an Aztec symbol to the printer,
+// using the given AztecConfig for options such as layers and error
+// correction.
+func (client *Client) WriteAztec(data string, cfg AztecConfig) error {
+	aztec, ok := client.profile.(Aztec)
+	if !ok {
+		return unsupported("Aztec")
+	}
+
+	var buf commandBuffer
+	buf.add(DefaultFormatConfig().Justify(cfg.justification).commands(client.profile))
+	buf.add(aztec.SelectAztecModeCommand(&cfg))
+	buf.add(aztec.SetAztecModuleSizeCommand(&cfg))
+	buf.add(aztec.SetAztecErrorCorrectionCommand(&cfg))
+	buf.add(aztec.StoreAztecDataCommand(data))
+	buf.add(aztec.PrintAztecDataCommand())
+	buf.add(DefaultFormatConfig().commands(client.profile))
+	return client.writeCommands(&buf)
+}
+
+// Status queries every status of the printer, waiting at most the
+// timeout for each reply.
+func (client *Client) Status(timeout time.Duration) (Status, error) {
+	return client.queryStatus(timeout, "printer", "offline", "error", "paper")
+}
+
+// PrinterStatus queries whether the printer is offline, whether its feed
+// button is pressed and the level of the drawer kick-out connector pin,
+// waiting at most the timeout for the reply.
+func (client *Client) PrinterStatus(timeout time.Duration) (Status, error) {
+	return client.queryStatus(timeout, "printer")
+}
+
+// DrawerOpen queries whether the cash drawer is open, waiting at most
+// the timeout for the reply. The drawer is taken to be open when pin 3
+// of the drawer kick-out connector is low, which is the case for most
+// drawers. For drawers whose switch works the other way round,
+// PrinterStatus reports the level of the pin.
+func (client *Client) DrawerOpen(timeout time.Duration) (bool, error) {
+	status, err := client.queryStatus(timeout, "printer")
+	return err == nil && !status.DrawerPinHigh, err
+}
+
+// OfflineStatus queries why the printer is offline: whether its cover
+// is open, paper is being fed, it has run out of paper or an error has
+// occurred, waiting at most the timeout for the reply.
+func (client *Client) OfflineStatus(timeout time.Duration) (Status, error) {
+	return client.queryStatus(timeout, "offline")
+}
+
+// ErrorStatus queries which errors have occurred: cutter errors,
+// unrecoverable errors and automatically recoverable errors, waiting at
+// most the timeout for the reply.
+func (client *Client) ErrorStatus(timeout time.Duration) (Status, error) {
+	return client.queryStatus(timeout, "error")
+}
+
+// PaperStatus queries whether the roll paper is nearly used up or has
+// run out, waiting at most the timeout for the reply.
+func (client *Client) PaperStatus(timeout time.Duration) (Status, error) {
+	return client.queryStatus(timeout, "paper")
+}
+
+// queryStatus queries each named status in turn and decodes the replies
+// into a single Status.
+func (client *Client) queryStatus(timeout time.Duration, kinds ...string) (Status, error) {
+	var status Status
+	if client.replies == nil {
+		return status, ErrNotBidirectional
+	}
+	realTime, ok := client.profile.(RealTimeStatus)
+	if !ok {
+		return status, unsupported("real-time status")
+	}
+
+	replies := client.replies
+	for _, kind := range kinds {
+		var buf commandBuffer
+		buf.add(realTime.StatusCommand(kind))
+
+		replies.discard()
+		if err := client.writeCommands(&buf); err != nil {
+			return Status{}, err
+		}
+
+		reply, err := replies.next(timeout)
+		if err != nil {
+			return Status{}, err
+		}
+
+		if err := realTime.DecodeStatus(kind, reply, &status); err != nil {
+			return Status{}, err
+		}
+	}
+	return status, nil
+}
+
+// Identify queries the printer for its model, firmware version,
+// manufacturer and serial number, waiting at most the timeout for each
+// reply. Information the printer does not reply with in time is left
+// unset, as many printers only report some of it. ErrTimeout is
+// returned only if the printer replies to none of the queries.
+func (client *Client) Identify(timeout time.Duration) (PrinterInfo, error) {
+	var info PrinterInfo
+	if client.replies == nil {
+		return info, ErrNotBidirectional
+	}
+	identification, ok := client.profile.(Identification)
+	if !ok {
+		return info, unsupported("identification")
 	}
-	client.writeString(c)
 
-	DefaultFormatConfig().apply(client, client.profile)
+	replies := client.replies
+	reported := false
+	for _, kind := range []string{"model ID", "type ID", "firmware version", "manufacturer", "model name", "serial number"} {
+		var buf commandBuffer
+		buf.add(identification.IdentificationCommand(kind))
+
+		replies.discard()
+		if err := client.writeCommands(&buf); err != nil {
+			return PrinterInfo{}, err
+		}
+
+		reply, err := replies.nextReply(timeout)
+		if errors.Is(err, ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			return PrinterInfo{}, err
+		}
+
+		if err := identification.DecodeIdentification(kind, reply, &info); err != nil {
+			return PrinterInfo{}, err
+		}
+		reported = true
+	}
+
+	if !reported {
+		return PrinterInfo{}, ErrTimeout
+	}
+	return info, nil
+}
+
+// EnableAutomaticStatusBack makes the printer send its status whenever
+// it changes, and returns a channel of events for the changes, such as
+// the paper running low or the cover being opened. The printer also
+// sends its status when automatic status back is enabled, so events for
+// the current status arrive straight away. Events are dropped if the
+// channel is not read and fills up. Queries can still be made while
+// automatic status back is enabled. The channel is closed by Close, or
+// when reading from the printer fails.
+func (client *Client) EnableAutomaticStatusBack() (<-chan Event, error) {
+	if client.replies == nil {
+		return nil, ErrNotBidirectional
+	}
+	asb, ok := client.profile.(AutomaticStatusBack)
+	if !ok {
+		return nil, unsupported("automatic status back")
+	}
+
+	var buf commandBuffer
+	buf.add(asb.EnableAutomaticStatusBackCommand())
+	if buf.err != nil {
+		return nil, buf.err
+	}
+
+	replies := client.replies
+	replies.mu.Lock()
+	select {
+	case <-replies.stopped:
+		replies.mu.Unlock()
+		return nil, ErrClosed
+	case <-replies.done:
+		replies.mu.Unlock()
+		return nil, &ReadError{Err: replies.err}
+	default:
+	}
+	if replies.events == nil {
+		replies.events = make(chan Event, 64)
+	}
+	replies.asb = asb
+	events := replies.events
+	replies.mu.Unlock()
+
+	return events, client.writeCommands(&buf)
+}
+
+// Close disables automatic status back if it was enabled, stops the
+// background reader, closes the events channel and closes the transport
+// if it is an io.Closer. Close does not wait for the reader, which
+// stops as soon as its pending read returns: when the transport is
+// closed, or otherwise once the printer next sends a byte. The reader
+// of a transport which is not an io.Closer, or whose reads are not
+// interrupted by closing it, stays blocked until then. Methods reading
+// from the printer return ErrClosed afterwards.
+func (client *Client) Close() error {
+	var err error
+	if client.replies != nil {
+		client.replies.mu.Lock()
+		asb := client.replies.asb
+		client.replies.mu.Unlock()
+		if asb != nil {
+			var buf commandBuffer
+			buf.add(asb.DisableAutomaticStatusBackCommand())
+			err = client.writeCommands(&buf)
+		}
+		client.replies.stop()
+	}
+
+	if closer, ok := client.writer.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
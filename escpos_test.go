@@ -2,12 +2,26 @@ package escpos
 
 import (
 	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"net"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/image/font/gofont/goregular"
 )
 
 func TestNewClient(t *testing.T) {
 	var writer bytes.Buffer
-	NewClient(&writer, EpsonTMT20III{})
+	_, err := NewClient(&writer, EpsonTMT20III{})
+
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
 
 	got := writer.Bytes()
 	want := []byte{'\x1B', '@'}
@@ -19,10 +33,12 @@ func TestNewClient(t *testing.T) {
 
 func TestClient_WriteLine(t *testing.T) {
 	var writer bytes.Buffer
-	client := NewClient(&writer, EpsonTMT20III{})
+	client, _ := NewClient(&writer, EpsonTMT20III{})
 	writer.Reset()
 
-	client.WriteLine("Hello!")
+	if err := client.WriteLine("Hello!"); err != nil {
+		t.Fatalf("WriteLine returned error: %v", err)
+	}
 
 	got := writer.Bytes()
 	want := []byte{'H', 'e', 'l', 'l', 'o', '!', '\n'}
@@ -34,25 +50,74 @@ func TestClient_WriteLine(t *testing.T) {
 
 func TestClient_Cut(t *testing.T) {
 	var writer bytes.Buffer
-	client := NewClient(&writer, EpsonTMT20III{})
+	client, _ := NewClient(&writer, EpsonTMT20III{})
 	writer.Reset()
 
-	client.Cut()
+	if err := client.Cut(); err != nil {
+		t.Fatalf("Cut returned error: %v", err)
+	}
 
 	got := writer.Bytes()
-	want := []byte{'\x1D', 'V', 'A', '0'}
+	want := []byte{'\x1D', 'V', 'A', 0}
 
 	if !bytes.Equal(got, want) {
 		t.Errorf("Cut did not write expected bytes, buffer got %s, wanted %s", got, want)
 	}
 }
 
+func TestClient_CutWithConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile Profile
+		cfg     CutConfig
+		want    string
+	}{
+		{"printer feeds to its cutter itself", EpsonTMT20III{}, DefaultCutConfig().Mode("partial"), "\x1DVB\x00"},
+		{"printer with a different cutter distance feeds to its cutter itself", EpsonTMT88VII{}, DefaultCutConfig(), "\x1DVA\x00"},
+		{"cut without feed", EpsonTMT20III{}, DefaultCutConfig().Feed(false), "\x1DV0"},
+		{"client feeds to the cutter position of the profile", Generic58mm{}, DefaultCutConfig(), "\x1BJ\x60\x1DV0"},
+		{"client does not feed without feed", Generic58mm{}, DefaultCutConfig().Mode("partial").Feed(false), "\x1DV1"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var writer bytes.Buffer
+			client, _ := NewClient(&writer, testCase.profile)
+			writer.Reset()
+
+			err := client.CutWithConfig(testCase.cfg)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if writer.String() != testCase.want {
+				t.Errorf("CutWithConfig did not write expected bytes: wanted %q, got %q", testCase.want, writer.String())
+			}
+		})
+	}
+
+	t.Run("invalid mode writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+		writer.Reset()
+
+		err := client.CutWithConfig(DefaultCutConfig().Mode("perforated"))
+
+		if !errors.Is(err, ErrInvalidConfig) || writer.Len() != 0 {
+			t.Errorf("CutWithConfig did not return config error without writing, got %v and %q", err, writer.String())
+		}
+	})
+}
+
 func TestClient_End(t *testing.T) {
 	var writer bytes.Buffer
-	client := NewClient(&writer, EpsonTMT20III{})
+	client, _ := NewClient(&writer, EpsonTMT20III{})
 	writer.Reset()
 
-	client.End()
+	if err := client.End(); err != nil {
+		t.Fatalf("End returned error: %v", err)
+	}
 
 	got := writer.Bytes()
 	want := []byte{'\xFA'}
@@ -61,3 +126,1875 @@ func TestClient_End(t *testing.T) {
 		t.Errorf("End did not write expected bytes, buffer got %s, wanted %s", got, want)
 	}
 }
+
+type failingWriter struct {
+	accept int
+	err    error
+}
+
+func (writer *failingWriter) Write(data []byte) (int, error) {
+	n := min(writer.accept, len(data))
+	writer.accept -= n
+	return n, writer.err
+}
+
+func TestClient_WriteErrors(t *testing.T) {
+	t.Run("writer error is returned as WriteError", func(t *testing.T) {
+		writeErr := errors.New("device unplugged")
+		client := Client{writer: &failingWriter{accept: 2, err: writeErr}, profile: EpsonTMT20III{}}
+
+		err := client.WriteLine("Hello!")
+
+		var got *WriteError
+		if !errors.As(err, &got) {
+			t.Fatalf("WriteLine did not return a WriteError, got %v", err)
+		}
+
+		if !errors.Is(err, writeErr) {
+			t.Errorf("WriteError did not wrap the writer error, got %v", got.Err)
+		}
+
+		if got.Written != 2 || string(got.Pending) != "llo!\n" {
+			t.Errorf("WriteError did not record partial write, got written %v, pending %q", got.Written, got.Pending)
+		}
+	})
+
+	t.Run("short write without error is returned as WriteError", func(t *testing.T) {
+		client := Client{writer: &failingWriter{accept: 3}, profile: EpsonTMT20III{}}
+
+		err := client.WriteLine("Hello!")
+
+		if !errors.Is(err, io.ErrShortWrite) {
+			t.Errorf("WriteLine did not return short write error, got %v", err)
+		}
+	})
+}
+
+func TestClient_Write(t *testing.T) {
+	t.Run("formatted text is written followed by format reset", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.Write("Hi", DefaultFormatConfig().Emphasize(true))
+
+		if err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+
+		want := "\x1BM0\x1Ba0\x1BE1\x1B-0\x1D!\x00Hi\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00"
+
+		if writer.String() != want {
+			t.Errorf("Write did not write expected bytes, buffer got %q, wanted %q", writer.String(), want)
+		}
+	})
+
+	t.Run("styles are turned off after the text", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.Write("TOTAL\n", DefaultFormatConfig().Reverse(true).UpsideDown(true))
+
+		if err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+
+		want := "\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00\x1DB1\x1B{1TOTAL\n\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00\x1DB0\x1B{0"
+
+		if writer.String() != want {
+			t.Errorf("Write did not write expected bytes, buffer got %q, wanted %q", writer.String(), want)
+		}
+	})
+
+	t.Run("upside-down text without a newline returns config error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.Write("TOTAL", DefaultFormatConfig().Reverse(true).UpsideDown(true))
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Write did not return config error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("Write wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("unsupported style returns unsupported error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+		writer.Reset()
+
+		err := client.Write("Hi", DefaultFormatConfig().CharSize(2, 2).Smoothing(true))
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Write did not return unsupported error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("Write wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("invalid format returns config error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.Write("Hi", DefaultFormatConfig().Underline("blabla"))
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Write did not return config error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("Write wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+}
+
+func TestClient_WriteQrCode(t *testing.T) {
+	t.Run("invalid size returns config error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.WriteQrCode("hello", DefaultQrCodeConfig().Size(17))
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("WriteQrCode did not return config error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("WriteQrCode wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+}
+
+type smallBandProfile struct {
+	EpsonTMT20III
+}
+
+func (smallBandProfile) MaxRasterBandHeight() int {
+	return 2
+}
+
+func (smallBandProfile) PrintableWidth() int {
+	return 8
+}
+
+func TestClient_WriteImage(t *testing.T) {
+	t.Run("image is justified and written as raster image", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		img := image.NewGray(image.Rect(0, 0, 4, 1))
+		img.Pix[0] = 0
+		img.Pix[1] = 255
+		img.Pix[2] = 100
+		img.Pix[3] = 200
+
+		err := client.WriteImage(img, DefaultImageConfig().Justify("right"))
+
+		if err != nil {
+			t.Fatalf("WriteImage returned error: %v", err)
+		}
+
+		want := "\x1BM0\x1Ba2\x1BE0\x1B-0\x1D!\x00" +
+			"\x1Dv0\x00\x01\x00\x01\x00\xA0" +
+			"\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00"
+
+		if writer.String() != want {
+			t.Errorf("WriteImage did not write expected bytes, buffer got %q, wanted %q", writer.String(), want)
+		}
+	})
+
+	t.Run("tall image is split into bands and scaled to printable width", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, smallBandProfile{})
+		writer.Reset()
+
+		img := image.NewRGBA(image.Rect(0, 0, 16, 10))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+		err := client.WriteImage(img, DefaultImageConfig())
+
+		if err != nil {
+			t.Fatalf("WriteImage returned error: %v", err)
+		}
+
+		band := "\x1Dv0\x00\x01\x00\x02\x00\xFF\xFF"
+		want := "\x1BM0\x1Ba1\x1BE0\x1B-0\x1D!\x00" + band + band + "\x1Dv0\x00\x01\x00\x01\x00\xFF" +
+			"\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00"
+
+		if writer.String() != want {
+			t.Errorf("WriteImage did not write expected bytes, buffer got %q, wanted %q", writer.String(), want)
+		}
+	})
+
+	t.Run("profile without raster support returns unsupported error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, struct{ Profile }{EpsonTMT20III{}})
+		writer.Reset()
+
+		err := client.WriteImage(image.NewGray(image.Rect(0, 0, 1, 1)), DefaultImageConfig())
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("WriteImage did not return unsupported error, got %v", err)
+		}
+	})
+}
+
+func TestClient_WriteBarcode(t *testing.T) {
+	t.Run("barcode is justified and written with settings", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		cfg := DefaultBarcodeConfig().Symbology("EAN-8").Width(2).Height(80).HriPosition("none").Justify("left")
+		err := client.WriteBarcode("96385074", cfg)
+
+		if err != nil {
+			t.Fatalf("WriteBarcode returned error: %v", err)
+		}
+
+		want := "\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00" +
+			"\x1Dw\x02\x1Dh\x50\x1DH0\x1Df0\x1DkD\x0896385074" +
+			"\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00"
+
+		if writer.String() != want {
+			t.Errorf("WriteBarcode did not write expected bytes, buffer got %q, wanted %q", writer.String(), want)
+		}
+	})
+
+	t.Run("invalid data returns config error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.WriteBarcode("96385075", DefaultBarcodeConfig().Symbology("EAN-8"))
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("WriteBarcode did not return config error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("WriteBarcode wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+}
+
+func TestClient_WriteBarcode_TooWide(t *testing.T) {
+	var writer bytes.Buffer
+	client, _ := NewClient(&writer, EpsonTMT20III{})
+	writer.Reset()
+
+	err := client.WriteBarcode("ORDER-REFERENCE-ABCDEFG", DefaultBarcodeConfig().Width(6))
+
+	want := "CODE128 barcode is too wide for the print area: 1728 dots > 576 dots (max)"
+
+	if !errors.Is(err, ErrInvalidConfig) || err.Error() != want {
+		t.Errorf("WriteBarcode did not return expected error, got %v, wanted %v", err, want)
+	}
+
+	if writer.Len() != 0 {
+		t.Errorf("WriteBarcode wrote bytes despite error, buffer got %q", writer.String())
+	}
+}
+
+func TestClient_WritePdf417(t *testing.T) {
+	var writer bytes.Buffer
+	client, _ := NewClient(&writer, EpsonTMT20III{})
+	writer.Reset()
+
+	err := client.WritePdf417("hi", DefaultPdf417Config().Columns(2).Truncated(true))
+
+	if err != nil {
+		t.Fatalf("WritePdf417 returned error: %v", err)
+	}
+
+	want := "\x1BM0\x1Ba1\x1BE0\x1B-0\x1D!\x00" +
+		"\x1D(k\x03\x000A\x02" +
+		"\x1D(k\x03\x000B\x00" +
+		"\x1D(k\x03\x000C\x03" +
+		"\x1D(k\x03\x000D\x03" +
+		"\x1D(k\x04\x000E1\x01" +
+		"\x1D(k\x03\x000F\x01" +
+		"\x1D(k\x05\x000P0hi" +
+		"\x1D(k\x03\x000Q0" +
+		"\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00"
+
+	if writer.String() != want {
+		t.Errorf("WritePdf417 did not write expected bytes, buffer got %q, wanted %q", writer.String(), want)
+	}
+}
+
+func TestClient_WriteDataMatrix(t *testing.T) {
+	t.Run("gs1 data is converted to an element string", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT88VII{})
+		writer.Reset()
+
+		err := client.WriteDataMatrix("(01)09501101530003(10)AB(21)1", DefaultDataMatrixConfig().Gs1(true))
+
+		if err != nil {
+			t.Fatalf("WriteDataMatrix returned error: %v", err)
+		}
+
+		want := "\x1D(k\x1C\x006P0\x1D010950110153000310AB\x1D211"
+
+		if !strings.Contains(writer.String(), want) {
+			t.Errorf("WriteDataMatrix did not write expected data, buffer got %q, wanted it to contain %q", writer.String(), want)
+		}
+	})
+
+	t.Run("profile without datamatrix support returns unsupported error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.WriteDataMatrix("hello", DefaultDataMatrixConfig())
+
+		var unsupportedErr *UnsupportedError
+		if !errors.As(err, &unsupportedErr) || unsupportedErr.Feature != "DataMatrix" {
+			t.Errorf("WriteDataMatrix did not return unsupported error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("WriteDataMatrix wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+}
+
+func TestClient_WriteQrCodeBytes(t *testing.T) {
+	t.Run("binary data is stored unmodified", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.WriteQrCodeBytes([]byte{0x00, 0xFF, 0x80}, DefaultQrCodeConfig())
+
+		if err != nil {
+			t.Fatalf("WriteQrCodeBytes returned error: %v", err)
+		}
+
+		want := "\x1D(k\x06\x001P0\x00\xFF\x80"
+
+		if !strings.Contains(writer.String(), want) {
+			t.Errorf("WriteQrCodeBytes did not store data, buffer got %q, wanted it to contain %q", writer.String(), want)
+		}
+	})
+
+	t.Run("symbol wider than print area returns config error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.WriteQrCodeBytes(bytes.Repeat([]byte("a"), 1000), DefaultQrCodeConfig().Size(8))
+
+		want := "QR code version 22 is too wide for the print area: 840 dots > 576 dots (max)"
+
+		if !errors.Is(err, ErrInvalidConfig) || err.Error() != want {
+			t.Errorf("WriteQrCodeBytes did not return expected error, got %v, wanted %v", err, want)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("WriteQrCodeBytes wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+}
+
+type structuredAppendProfile struct {
+	EpsonTMT20III
+}
+
+func (structuredAppendProfile) QrCodeStructuredAppendCommand(index int, total int, parity byte) (string, error) {
+	return string([]byte{'S', byte(index), byte(total), parity}), nil
+}
+
+func (structuredAppendProfile) PrintableWidth() int {
+	return 200
+}
+
+func TestClient_WriteQrCode_StructuredAppend(t *testing.T) {
+	data := strings.Repeat("a", 999) + "b"
+
+	t.Run("long data is split across linked symbols", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, structuredAppendProfile{})
+		writer.Reset()
+
+		err := client.WriteQrCode(data, DefaultQrCodeConfig().StructuredAppend(true))
+
+		if err != nil {
+			t.Fatalf("WriteQrCode returned error: %v", err)
+		}
+
+		for index := byte(0); index < 3; index++ {
+			want := string([]byte{'S', index, 3, 0x03}) + "\x1D(k"
+
+			if !strings.Contains(writer.String(), want) {
+				t.Errorf("WriteQrCode did not write symbol %v, wanted buffer to contain %q", index, want)
+			}
+		}
+
+		if got := strings.Count(writer.String(), "\x1D(k\x03\x001Q0"); got != 3 {
+			t.Errorf("WriteQrCode did not print expected symbols: wanted 3, got %v", got)
+		}
+	})
+
+	t.Run("side by side symbols are laid out in page mode", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, structuredAppendProfile{})
+		writer.Reset()
+
+		err := client.WriteQrCode(data, DefaultQrCodeConfig().StructuredAppend(true).SideBySide(true))
+
+		if err != nil {
+			t.Fatalf("WriteQrCode returned error: %v", err)
+		}
+
+		// Version 12 symbols are 195 dots wide, so only one fits across
+		// the 200 dot page and each row is 207 dots including the gap.
+		wantPrefix := "\x1BL\x1BW\x00\x00\x00\x00\xC8\x00\x61\x02\x1B$\x02\x00\x1D$\xC3\x00S\x00\x03\x03"
+		wantPositions := []string{"\x1B$\x02\x00\x1D$\x92\x01S\x01", "\x1B$\x02\x00\x1D$\x61\x02S\x02"}
+
+		if !strings.HasPrefix(writer.String(), wantPrefix) || !strings.HasSuffix(writer.String(), "\x0C") {
+			t.Errorf("WriteQrCode did not write page mode layout, buffer got %q, wanted prefix %q", writer.String(), wantPrefix)
+		}
+
+		for _, want := range wantPositions {
+			if !strings.Contains(writer.String(), want) {
+				t.Errorf("WriteQrCode did not position symbol, wanted buffer to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("data that fits is printed as a single symbol", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, structuredAppendProfile{})
+		writer.Reset()
+
+		err := client.WriteQrCode("hello", DefaultQrCodeConfig().StructuredAppend(true))
+
+		if err != nil {
+			t.Fatalf("WriteQrCode returned error: %v", err)
+		}
+
+		if strings.Contains(writer.String(), "S\x00") {
+			t.Errorf("WriteQrCode wrote structured append header for a single symbol, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("profile without structured append returns unsupported error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.WriteQrCode(strings.Repeat("a", 3000), DefaultQrCodeConfig().StructuredAppend(true))
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("WriteQrCode did not return unsupported error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("WriteQrCode wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+}
+
+func TestClient_RenderedSymbols(t *testing.T) {
+	t.Run("QR code is rendered as a raster image when the profile lacks QR codes", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+		writer.Reset()
+
+		err := client.WriteQrCode("hello", DefaultQrCodeConfig().Size(4))
+
+		if err != nil {
+			t.Fatalf("WriteQrCode returned error: %v", err)
+		}
+
+		// Version 1 is 21 modules, so 84 dots or 11 bytes across.
+		want := "\x1Dv0\x00\x0B\x00\x54\x00"
+
+		if !strings.Contains(writer.String(), want) {
+			t.Errorf("WriteQrCode did not write raster image, buffer got %q, wanted it to contain %q", writer.String(), want)
+		}
+	})
+
+	t.Run("structured append symbols are rendered side by side", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+		writer.Reset()
+
+		err := client.WriteQrCode(strings.Repeat("a", 3000), DefaultQrCodeConfig().Size(1).StructuredAppend(true).SideBySide(true))
+
+		if err != nil {
+			t.Fatalf("WriteQrCode returned error: %v", err)
+		}
+
+		// Two version 28 symbols of 129 dots, 4 dots apart, are 262 dots
+		// or 33 bytes across.
+		want := "\x1Dv0\x00\x21\x00\x81\x00"
+
+		if !strings.Contains(writer.String(), want) {
+			t.Errorf("WriteQrCode did not write tiled raster image, buffer got %q, wanted it to contain %q", writer.String(), want)
+		}
+	})
+
+	t.Run("barcode is rendered with HRI text when the profile lacks barcodes", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+		writer.Reset()
+
+		err := client.WriteBarcode("9638507", DefaultBarcodeConfig().Symbology("EAN-8").Width(2).Height(40).HriPosition("both").HriFont("B"))
+
+		if err != nil {
+			t.Fatalf("WriteBarcode returned error: %v", err)
+		}
+
+		hri := "\x1BM1\x1Ba1\x1BE0\x1B-0\x1D!\x0096385074\n"
+		raster := "\x1Dv0\x00\x11\x00\x28\x00"
+
+		if strings.Count(writer.String(), hri) != 2 || !strings.Contains(writer.String(), hri+"\x1BM0\x1Ba1\x1BE0\x1B-0\x1D!\x00"+raster) {
+			t.Errorf("WriteBarcode did not write HRI and raster image, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("rendered barcode wider than print area returns config error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+		writer.Reset()
+
+		err := client.WriteBarcode(strings.Repeat("A", 20), DefaultBarcodeConfig().Symbology("CODE39").Width(3))
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("WriteBarcode did not return config error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("WriteBarcode wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+}
+
+func TestClient_CodeTables(t *testing.T) {
+	t.Run("text is transcoded and the code table selected once", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		_ = client.WriteLine("café")
+		_ = client.WriteLine("naïve")
+
+		want := "caf\x1Bt\x00\x82\nna\x8Bve\n"
+
+		if writer.String() != want {
+			t.Errorf("WriteLine did not write expected bytes: wanted %q, got %q", want, writer.String())
+		}
+	})
+
+	t.Run("code table is switched for characters the active table lacks", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.WriteLine("café")
+		writer.Reset()
+
+		err := client.Write("5€", DefaultFormatConfig())
+
+		if err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+
+		if !strings.Contains(writer.String(), "5\x1Bt\x13\xD5\x1Bt\x00") {
+			t.Errorf("Write did not switch to CP858 and back, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("init forgets the active code table", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.WriteLine("café")
+		_ = client.Init()
+		writer.Reset()
+
+		_ = client.WriteLine("café")
+
+		if writer.String() != "caf\x1Bt\x00\x82\n" {
+			t.Errorf("WriteLine did not reselect code table after init, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("code table is switched mid-line for each run of characters", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.WriteLine("café")
+		writer.Reset()
+
+		_ = client.WriteLine("Żurek, Käse, борщ")
+
+		want := "\x1Bt\x12\xBDurek, K\x84se, \x1Bt\x11\xA1\xAE\xE0\xE9\n\x1Bt\x00"
+
+		if writer.String() != want {
+			t.Errorf("WriteLine did not write expected bytes: wanted %q, got %q", want, writer.String())
+		}
+	})
+
+	t.Run("ASCII text is written unchanged", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		_ = client.WriteLine("plain")
+
+		if writer.String() != "plain\n" {
+			t.Errorf("WriteLine did not write expected bytes, got %q", writer.String())
+		}
+	})
+
+	t.Run("error fallback returns encoding error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.SetCharacterFallback("error")
+		writer.Reset()
+
+		err := client.WriteLine("中文")
+
+		if !errors.Is(err, ErrUnencodable) {
+			t.Errorf("WriteLine did not return encoding error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("WriteLine wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("unknown fallback returns config error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		err := client.SetCharacterFallback("ignore")
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("SetCharacterFallback did not return config error, got %v", err)
+		}
+	})
+}
+
+func TestClient_MultiByteEncodings(t *testing.T) {
+	t.Run("kanji is printed in Shift-JIS and the code table restored", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{Encoding: "Shift-JIS"})
+		_ = client.WriteLine("café")
+		writer.Reset()
+
+		_ = client.WriteLine("日本 é")
+
+		want := "\x1CC\x01\x1C&\x93\xFA\x96{ \x1C.\x1CC\x00\x82\n"
+
+		if writer.String() != want {
+			t.Errorf("WriteLine did not write expected bytes: wanted %q, got %q", want, writer.String())
+		}
+	})
+
+	t.Run("multi-byte mode stays on when no table was active", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{Encoding: "GB18030"})
+		writer.Reset()
+
+		_ = client.WriteLine("中文")
+		_ = client.WriteLine("文")
+
+		want := "\x1C&\xD6\xD0\xCE\xC4\n\xCE\xC4\n"
+
+		if writer.String() != want {
+			t.Errorf("WriteLine did not write expected bytes: wanted %q, got %q", want, writer.String())
+		}
+	})
+
+	t.Run("code table is preferred for text both can print", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{Encoding: "GB18030"})
+		writer.Reset()
+
+		_ = client.WriteLine("café")
+
+		if writer.String() != "caf\x1Bt\x00\x82\n" {
+			t.Errorf("WriteLine did not write expected bytes, got %q", writer.String())
+		}
+	})
+
+	t.Run("models without multi-byte characters replace kanji", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		_ = client.WriteLine("日本")
+
+		if writer.String() != "??\n" {
+			t.Errorf("WriteLine did not write expected bytes, got %q", writer.String())
+		}
+	})
+}
+
+func TestClient_UTF8(t *testing.T) {
+	t.Run("text is sent unchanged after selecting UTF-8 once", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{UTF8: true})
+		writer.Reset()
+
+		_ = client.WriteLine("ASCII")
+		_ = client.WriteLine("日本 café")
+		_ = client.WriteLine("€5")
+
+		want := "ASCII\n\x1C(C\x02\x000\x02日本 café\n€5\n"
+
+		if writer.String() != want {
+			t.Errorf("WriteLine did not write expected bytes: wanted %q, got %q", want, writer.String())
+		}
+	})
+
+	t.Run("font priority is selected with UTF-8", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT88VII{})
+		_ = client.WriteLine("日本")
+		writer.Reset()
+
+		err := client.SetFontPriority("Simplified Chinese")
+		_ = client.WriteLine("直")
+
+		if err != nil {
+			t.Fatalf("SetFontPriority returned error: %v", err)
+		}
+
+		want := "\x1C(C\x02\x000\x02\x1C(C\x03\x00<\x00\x14直\n"
+
+		if writer.String() != want {
+			t.Errorf("WriteLine did not write expected bytes: wanted %q, got %q", want, writer.String())
+		}
+	})
+
+	t.Run("UTF-8 is selected again after init", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT88VII{})
+		_ = client.WriteLine("café")
+		_ = client.Init()
+		writer.Reset()
+
+		_ = client.WriteLine("café")
+
+		if writer.String() != "\x1C(C\x02\x000\x02café\n" {
+			t.Errorf("WriteLine did not reselect UTF-8 after init, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("firmware without UTF-8 falls back to code tables", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		_ = client.WriteLine("café")
+
+		if writer.String() != "caf\x1Bt\x00\x82\n" {
+			t.Errorf("WriteLine did not transcode text, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("font priority on firmware without UTF-8 returns unsupported error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		err := client.SetFontPriority("Japanese")
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("SetFontPriority did not return unsupported error, got %v", err)
+		}
+	})
+
+	t.Run("unknown font priority returns config error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT88VII{})
+
+		err := client.SetFontPriority("Klingon")
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("SetFontPriority did not return config error, got %v", err)
+		}
+	})
+}
+
+func TestClient_RasterText(t *testing.T) {
+	t.Run("lines the printer cannot print are rendered as raster images", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.SetRasterFont(goregular.TTF, 24)
+		writer.Reset()
+
+		err := client.Write("Menu\nשלום\nEnd", DefaultFormatConfig().Justify("right"))
+
+		if err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+
+		got := writer.String()
+		raster := strings.Index(got, "\x1Dv0")
+		if !strings.HasPrefix(got, "\x1BM0\x1Ba2\x1BE0\x1B-0\x1D!\x00Menu\n\x1Dv0") || !strings.HasSuffix(got, "End\x1BM0\x1Ba0\x1BE0\x1B-0\x1D!\x00") {
+			t.Errorf("Write did not print text around raster image, buffer got %q", got)
+		}
+
+		if strings.Count(got, "\x1Dv0") != 1 || raster < 0 || strings.Contains(got, "\x1Bt") {
+			t.Errorf("Write did not print the Hebrew line as one raster image, buffer got %q", got)
+		}
+	})
+
+	t.Run("raster image is as tall as the font's line", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.SetRasterFont(goregular.TTF, 24)
+		writer.Reset()
+
+		_ = client.WriteLine("سلام")
+
+		got := writer.Bytes()
+		if len(got) < 8 || !bytes.HasPrefix(got, []byte("\x1Dv0\x00")) {
+			t.Fatalf("WriteLine did not print a raster image, buffer got %q", got)
+		}
+
+		if height := int(got[6]) | int(got[7])<<8; height < 24 || height > 30 {
+			t.Errorf("raster image was %v dots tall, wanted the line height of a 24 dot font", height)
+		}
+	})
+
+	t.Run("emoji are rendered on printers accepting UTF-8 text", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT88VII{})
+		_ = client.SetRasterFont(goregular.TTF, 24)
+		writer.Reset()
+
+		_ = client.WriteLine("Pizza 🍕")
+
+		if !strings.HasPrefix(writer.String(), "\x1Dv0") {
+			t.Errorf("WriteLine did not print a raster image, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("emoji are rendered on printers printing GB18030", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{Encoding: "GB18030"})
+		_ = client.SetRasterFont(goregular.TTF, 24)
+		writer.Reset()
+
+		_ = client.WriteLine("Pizza 🍕")
+
+		if !strings.HasPrefix(writer.String(), "\x1Dv0") {
+			t.Errorf("WriteLine did not print a raster image, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("lines the printer can print are written as text", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.SetRasterFont(goregular.TTF, 24)
+		writer.Reset()
+
+		_ = client.WriteLine("café")
+
+		if writer.String() != "caf\x1Bt\x00\x82\n" {
+			t.Errorf("WriteLine did not write expected bytes, got %q", writer.String())
+		}
+	})
+
+	t.Run("without a raster font text is transcoded", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		_ = client.WriteLine("🍕")
+
+		if writer.String() != "?\n" {
+			t.Errorf("WriteLine did not write expected bytes, got %q", writer.String())
+		}
+	})
+
+	negativeCases := []struct {
+		name string
+		data []byte
+		size float64
+	}{
+		{"invalid font data", []byte("not a font"), 24},
+		{"zero size", goregular.TTF, 0},
+		{"negative size", goregular.TTF, -1},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name+" returns config error", func(t *testing.T) {
+			var writer bytes.Buffer
+			client, _ := NewClient(&writer, EpsonTMT20III{})
+
+			err := client.SetRasterFont(testCase.data, testCase.size)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("SetRasterFont did not return config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_SetInternationalCharacterSet(t *testing.T) {
+	t.Run("set is selected and pound printed without a code table switch", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.SetInternationalCharacterSet("UK")
+		_ = client.WriteLine("£5 #1")
+
+		if err != nil {
+			t.Fatalf("SetInternationalCharacterSet returned error: %v", err)
+		}
+
+		want := "\x1BR\x03#5 ?1\n"
+
+		if writer.String() != want {
+			t.Errorf("client did not write expected bytes: wanted %q, got %q", want, writer.String())
+		}
+	})
+
+	t.Run("set is selected again after init", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		_ = client.SetInternationalCharacterSet("Germany")
+		writer.Reset()
+
+		_ = client.Init()
+
+		if writer.String() != "\x1B@\x1BR\x02" {
+			t.Errorf("Init did not select international character set, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("unknown set returns config error and writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.SetInternationalCharacterSet("Atlantis")
+
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("SetInternationalCharacterSet did not return config error, got %v", err)
+		}
+
+		if writer.Len() != 0 {
+			t.Errorf("SetInternationalCharacterSet wrote bytes despite error, buffer got %q", writer.String())
+		}
+	})
+
+	t.Run("profile without international character sets returns unsupported error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+
+		err := client.SetInternationalCharacterSet("UK")
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("SetInternationalCharacterSet did not return unsupported error, got %v", err)
+		}
+	})
+}
+
+// fakePrinter records the commands written to it and replies to each
+// real-time status command with the next of its canned replies, to
+// each process ID command with the process ID unless it is offline, and
+// to each identification command with the reply held for it. Bytes sent
+// on unprompted are read as if the printer sent them by itself.
+type fakePrinter struct {
+	bytes.Buffer
+	canned     []byte
+	offline    bool
+	identity   map[byte]string
+	unprompted chan byte
+}
+
+func newFakePrinter(canned ...byte) *fakePrinter {
+	return &fakePrinter{canned: canned, unprompted: make(chan byte, 16)}
+}
+
+func (printer *fakePrinter) Write(data []byte) (int, error) {
+	for i := 0; i+1 < len(data); i++ {
+		if data[i] == '\x10' && data[i+1] == '\x04' && len(printer.canned) > 0 {
+			printer.unprompted <- printer.canned[0]
+			printer.canned = printer.canned[1:]
+		}
+		if data[i] == '\x1D' && data[i+1] == 'I' && i+2 < len(data) {
+			for _, b := range []byte(printer.identity[data[i+2]]) {
+				printer.unprompted <- b
+			}
+		}
+		if bytes.HasPrefix(data[i:], []byte("\x1D(H\x06\x0000")) && i+11 <= len(data) && !printer.offline {
+			for _, b := range append(append([]byte{0x37, 0x22}, data[i+7:i+11]...), 0x00) {
+				printer.unprompted <- b
+			}
+		}
+	}
+	return printer.Buffer.Write(data)
+}
+
+func (printer *fakePrinter) Close() error {
+	close(printer.unprompted)
+	return nil
+}
+
+func (printer *fakePrinter) Read(data []byte) (int, error) {
+	reply, ok := <-printer.unprompted
+	if !ok {
+		return 0, io.EOF
+	}
+	data[0] = reply
+	return 1, nil
+}
+
+func TestClient_Status(t *testing.T) {
+	t.Run("status queries are sent and replies decoded", func(t *testing.T) {
+		printer := newFakePrinter(0x1E, 0x16, 0x1A, 0x1E)
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		printer.Reset()
+
+		got, err := client.Status(time.Second)
+
+		if err != nil {
+			t.Fatalf("Status returned error: %v", err)
+		}
+
+		want := Status{DrawerPinHigh: true, Offline: true, CoverOpen: true, CutterError: true, PaperNearEnd: true}
+
+		if got != want {
+			t.Errorf("Status did not return expected status: wanted %+v, got %+v", want, got)
+		}
+
+		if printer.String() != "\x10\x04\x01\x10\x04\x02\x10\x04\x03\x10\x04\x04" {
+			t.Errorf("Status did not write expected bytes, got %q", printer.String())
+		}
+	})
+
+	t.Run("single status is queried", func(t *testing.T) {
+		printer := newFakePrinter(0x72)
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		printer.Reset()
+
+		got, err := client.PaperStatus(time.Second)
+
+		if err != nil || got != (Status{PaperEnd: true}) {
+			t.Errorf("PaperStatus did not return paper end, got %+v, err %v", got, err)
+		}
+
+		if printer.String() != "\x10\x04\x04" {
+			t.Errorf("PaperStatus did not write expected bytes, got %q", printer.String())
+		}
+	})
+
+	t.Run("late reply after timeout is discarded", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+
+		_, err := client.OfflineStatus(10 * time.Millisecond)
+
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("OfflineStatus did not return timeout error, got %v", err)
+		}
+
+		printer.unprompted <- 0x16
+		printer.canned = []byte{0x72}
+		time.Sleep(20 * time.Millisecond)
+		got, err := client.PaperStatus(time.Second)
+
+		if err != nil || got != (Status{PaperEnd: true}) {
+			t.Errorf("PaperStatus did not return paper end, got %+v, err %v", got, err)
+		}
+	})
+
+	t.Run("pipe transport without a reply times out", func(t *testing.T) {
+		clientEnd, printerEnd := net.Pipe()
+		defer clientEnd.Close()
+		defer printerEnd.Close()
+		go func() {
+			_, _ = io.Copy(io.Discard, printerEnd)
+		}()
+		client, _ := NewBidirectionalClient(clientEnd, EpsonTMT20III{})
+
+		_, err := client.PrinterStatus(10 * time.Millisecond)
+
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("PrinterStatus did not return timeout error, got %v", err)
+		}
+	})
+
+	t.Run("pipe transport reads the reply", func(t *testing.T) {
+		clientEnd, printerEnd := net.Pipe()
+		defer clientEnd.Close()
+		defer printerEnd.Close()
+		go func() {
+			command := make([]byte, 3)
+			_, _ = io.ReadFull(printerEnd, command[:2])
+			_, _ = io.ReadFull(printerEnd, command)
+			_, _ = printerEnd.Write([]byte{0x16})
+		}()
+		client, _ := NewBidirectionalClient(clientEnd, EpsonTMT20III{})
+
+		got, err := client.OfflineStatus(time.Second)
+
+		if err != nil || got != (Status{CoverOpen: true}) {
+			t.Errorf("OfflineStatus did not return cover open, got %+v, err %v", got, err)
+		}
+	})
+
+	t.Run("invalid reply returns status error", func(t *testing.T) {
+		printer := newFakePrinter(0x00)
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+
+		_, err := client.PaperStatus(time.Second)
+
+		if !errors.Is(err, ErrInvalidStatus) {
+			t.Errorf("PaperStatus did not return status error, got %v", err)
+		}
+	})
+
+	t.Run("reader failure returns read error", func(t *testing.T) {
+		printer := newFakePrinter()
+		close(printer.unprompted)
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+
+		_, err := client.PaperStatus(time.Second)
+
+		var readErr *ReadError
+		if !errors.As(err, &readErr) || !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			t.Errorf("PaperStatus did not return read error, got %v", err)
+		}
+	})
+
+	t.Run("client without reader returns error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		_, err := client.PaperStatus(time.Second)
+
+		if !errors.Is(err, ErrNotBidirectional) {
+			t.Errorf("PaperStatus did not return not bidirectional error, got %v", err)
+		}
+	})
+}
+
+func TestClient_Copies(t *testing.T) {
+	printer := newFakePrinter(0x16, 0x12, 0x1E, 0x12)
+	client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+	copied := client
+
+	query := func(client Client) Status {
+		t.Helper()
+		status, err := client.PrinterStatus(time.Second)
+		if err != nil {
+			t.Fatalf("PrinterStatus returned error: %v", err)
+		}
+		return status
+	}
+
+	for i, want := range []bool{true, false, true, false} {
+		queried := client
+		if i%2 == 1 {
+			queried = copied
+		}
+
+		if got := query(queried); got.DrawerPinHigh != want {
+			t.Errorf("query %v returned drawer pin high %v, wanted %v", i, got.DrawerPinHigh, want)
+		}
+	}
+
+	t.Run("copies send different process IDs", func(t *testing.T) {
+		printer.Reset()
+		_ = client.Commit(time.Second)
+		first := printer.String()
+		printer.Reset()
+		_ = copied.Commit(time.Second)
+
+		if first == printer.String() {
+			t.Errorf("copies sent the same process ID: %q", first)
+		}
+	})
+}
+
+func TestClient_AutomaticStatusBack(t *testing.T) {
+	receive := func(t *testing.T, events <-chan Event) Event {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatalf("no event was received")
+			return Event{}
+		}
+	}
+
+	t.Run("status messages are published as events", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		printer.Reset()
+
+		events, err := client.EnableAutomaticStatusBack()
+
+		if err != nil {
+			t.Fatalf("EnableAutomaticStatusBack returned error: %v", err)
+		}
+
+		if printer.String() != "\x1Da\x0F" {
+			t.Errorf("EnableAutomaticStatusBack did not write expected bytes, got %q", printer.String())
+		}
+
+		for _, b := range []byte{0x10, 0x00, 0x03, 0x00, 0x30, 0x00, 0x03, 0x00} {
+			printer.unprompted <- b
+		}
+
+		if event := receive(t, events); event.Kind != EventPaperLow {
+			t.Errorf("first event was %v, wanted %v", event.Kind, EventPaperLow)
+		}
+
+		if event := receive(t, events); event.Kind != EventCoverOpened || !event.Status.CoverOpen {
+			t.Errorf("second event was %+v, wanted %v", event, EventCoverOpened)
+		}
+	})
+
+	t.Run("status messages are separated from query replies", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		events, _ := client.EnableAutomaticStatusBack()
+
+		for _, b := range []byte{0x10, 0x00, 0x0F, 0x00} {
+			printer.unprompted <- b
+		}
+		printer.canned = []byte{0x16}
+		got, err := client.OfflineStatus(time.Second)
+
+		if err != nil || got != (Status{CoverOpen: true}) {
+			t.Errorf("OfflineStatus did not return cover open, got %+v, err %v", got, err)
+		}
+
+		if event := receive(t, events); event.Kind != EventPaperLow {
+			t.Errorf("first event was %v, wanted %v", event.Kind, EventPaperLow)
+		}
+
+		if event := receive(t, events); event.Kind != EventPaperOut {
+			t.Errorf("second event was %v, wanted %v", event.Kind, EventPaperOut)
+		}
+	})
+
+	t.Run("close disables automatic status back and closes events", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		events, _ := client.EnableAutomaticStatusBack()
+		printer.Reset()
+
+		err := client.Close()
+
+		if err != nil {
+			t.Fatalf("Close returned error: %v", err)
+		}
+
+		if printer.String() != "\x1Da\x00" {
+			t.Errorf("Close did not write expected bytes, got %q", printer.String())
+		}
+
+		if _, ok := <-events; ok {
+			t.Errorf("events channel was not closed")
+		}
+	})
+
+	t.Run("close stops the reader of a transport which cannot be closed", func(t *testing.T) {
+		printer := newFakePrinter()
+		transport := struct {
+			io.Reader
+			io.Writer
+		}{printer, printer}
+		client, _ := NewBidirectionalClient(transport, EpsonTMT20III{})
+		events, _ := client.EnableAutomaticStatusBack()
+		printer.Reset()
+
+		closed := make(chan error)
+		go func() { closed <- client.Close() }()
+
+		select {
+		case err := <-closed:
+			if err != nil {
+				t.Fatalf("Close returned error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Close did not return while the reader was blocked")
+		}
+
+		if printer.String() != "\x1Da\x00" {
+			t.Errorf("Close did not write expected bytes, got %q", printer.String())
+		}
+
+		if _, ok := <-events; ok {
+			t.Errorf("events channel was not closed")
+		}
+
+		if _, err := client.PaperStatus(time.Second); !errors.Is(err, ErrClosed) {
+			t.Errorf("PaperStatus did not return closed error, got %v", err)
+		}
+
+		// The blocked read returns once the printer sends a byte, and the
+		// reader then stops.
+		printer.unprompted <- 0x12
+		select {
+		case <-client.replies.done:
+		case <-time.After(time.Second):
+			t.Errorf("reader did not stop after its read returned")
+		}
+	})
+
+	t.Run("client without reader returns error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		_, err := client.EnableAutomaticStatusBack()
+
+		if !errors.Is(err, ErrNotBidirectional) {
+			t.Errorf("EnableAutomaticStatusBack did not return not bidirectional error, got %v", err)
+		}
+	})
+
+	t.Run("profile without automatic status back returns unsupported error", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, Generic58mm{})
+
+		_, err := client.EnableAutomaticStatusBack()
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("EnableAutomaticStatusBack did not return unsupported error, got %v", err)
+		}
+	})
+}
+
+func TestClient_Commit(t *testing.T) {
+	t.Run("commit ends and waits for the process ID", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		printer.Reset()
+
+		err := client.Commit(time.Second)
+
+		if err != nil {
+			t.Fatalf("Commit returned error: %v", err)
+		}
+
+		got := printer.String()
+		if !strings.HasPrefix(got, "\xFA\x1D(H\x06\x0000") || len(got) != 12 {
+			t.Errorf("Commit did not write expected bytes, got %q", got)
+		}
+	})
+
+	t.Run("each commit sends a new process ID", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		printer.Reset()
+
+		_ = client.Commit(time.Second)
+		first := printer.String()
+		printer.Reset()
+		_ = client.Commit(time.Second)
+
+		if first == printer.String() {
+			t.Errorf("Commit sent the same process ID twice: %q", first)
+		}
+	})
+
+	t.Run("response to an earlier process ID is not confirmation", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		printer.offline = true
+
+		err := client.Commit(10 * time.Millisecond)
+
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("Commit did not return timeout error, got %v", err)
+		}
+
+		// The printer comes back online and prints the first job,
+		// after its commit timed out.
+		stale := printer.Bytes()[len(printer.Bytes())-4:]
+		for _, b := range append(append([]byte{0x37, 0x22}, stale...), 0x00) {
+			printer.unprompted <- b
+		}
+		printer.Reset()
+		err = client.Commit(10 * time.Millisecond)
+
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("Commit did not return timeout error, got %v", err)
+		}
+
+		printer.offline = false
+		err = client.Commit(time.Second)
+
+		if err != nil {
+			t.Errorf("Commit returned error: %v", err)
+		}
+	})
+
+	t.Run("status replies are not mistaken for process IDs", func(t *testing.T) {
+		printer := newFakePrinter(0x12)
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+
+		err := client.Commit(time.Second)
+		if err != nil {
+			t.Fatalf("Commit returned error: %v", err)
+		}
+
+		got, err := client.PrinterStatus(time.Second)
+
+		if err != nil || got != (Status{}) {
+			t.Errorf("PrinterStatus did not return expected status, got %+v, err %v", got, err)
+		}
+	})
+
+	t.Run("client without reader returns error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		err := client.Commit(time.Second)
+
+		if !errors.Is(err, ErrNotBidirectional) {
+			t.Errorf("Commit did not return not bidirectional error, got %v", err)
+		}
+	})
+
+	t.Run("profile without process ID returns unsupported error", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, Generic58mm{})
+
+		err := client.Commit(time.Second)
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Commit did not return unsupported error, got %v", err)
+		}
+	})
+}
+
+func TestClient_Identify(t *testing.T) {
+	identity := func(modelName string) map[byte]string {
+		return map[byte]string{
+			0x01: "\x20",
+			0x02: "\x02",
+			'A':  "_30.01 ESC/POS\x00",
+			'B':  "_EPSON\x00",
+			'C':  "_" + modelName + "\x00",
+			'D':  "_X7P0001234\x00",
+		}
+	}
+
+	t.Run("printer information is queried and decoded", func(t *testing.T) {
+		printer := newFakePrinter()
+		printer.identity = identity("TM-T20III")
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		printer.Reset()
+
+		got, err := client.Identify(time.Second)
+
+		if err != nil {
+			t.Fatalf("Identify returned error: %v", err)
+		}
+
+		want := PrinterInfo{
+			ModelID:         0x20,
+			TypeID:          0x02,
+			FirmwareVersion: "30.01 ESC/POS",
+			Manufacturer:    "EPSON",
+			ModelName:       "TM-T20III",
+			SerialNumber:    "X7P0001234",
+		}
+
+		if got != want {
+			t.Errorf("Identify did not return expected information: wanted %+v, got %+v", want, got)
+		}
+
+		if printer.String() != "\x1DI\x01\x1DI\x02\x1DIA\x1DIB\x1DIC\x1DID" {
+			t.Errorf("Identify did not write expected bytes, got %q", printer.String())
+		}
+	})
+
+	t.Run("replies are not mistaken for other messages", func(t *testing.T) {
+		printer := newFakePrinter()
+		printer.identity = identity("TM-T20III")
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+		events, _ := client.EnableAutomaticStatusBack()
+
+		got, err := client.Identify(time.Second)
+
+		if err != nil || got.SerialNumber != "X7P0001234" {
+			t.Errorf("Identify did not return expected serial number, got %q, err %v", got.SerialNumber, err)
+		}
+
+		select {
+		case event := <-events:
+			t.Errorf("reply was published as event %+v", event)
+		default:
+		}
+	})
+
+	t.Run("information the printer does not reply with is left unset", func(t *testing.T) {
+		printer := newFakePrinter()
+		printer.identity = map[byte]string{0x01: "\x20", 0x02: "\x02"}
+		client, _ := NewBidirectionalClient(printer, Generic58mm{})
+
+		got, err := client.Identify(10 * time.Millisecond)
+
+		if err != nil {
+			t.Fatalf("Identify returned error: %v", err)
+		}
+
+		if got != (PrinterInfo{ModelID: 0x20, TypeID: 0x02}) {
+			t.Errorf("Identify did not return the information reported, got %+v", got)
+		}
+	})
+
+	t.Run("printer which does not reply returns timeout error", func(t *testing.T) {
+		printer := newFakePrinter()
+		client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+
+		_, err := client.Identify(10 * time.Millisecond)
+
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("Identify did not return timeout error, got %v", err)
+		}
+	})
+
+	t.Run("client without reader returns error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		_, err := client.Identify(time.Second)
+
+		if !errors.Is(err, ErrNotBidirectional) {
+			t.Errorf("Identify did not return not bidirectional error, got %v", err)
+		}
+	})
+
+	cases := []struct {
+		name      string
+		modelName string
+		want      Profile
+	}{
+		{"registered model uses its profile", "TM-T88VII", EpsonTMT88VII{}},
+		{"unknown model uses fallback profile", "POS-58", Generic58mm{}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			printer := newFakePrinter()
+			printer.identity = identity(testCase.modelName)
+
+			client, err := NewDetectedClient(printer, DefaultProfileRegistry(), time.Second)
+
+			if err != nil {
+				t.Fatalf("NewDetectedClient returned error: %v", err)
+			}
+
+			if client.profile != testCase.want {
+				t.Errorf("NewDetectedClient chose %T, wanted %T", client.profile, testCase.want)
+			}
+
+			if !strings.HasSuffix(printer.String(), "\x1DID\x1B@") {
+				t.Errorf("printer was not initialised after it was identified, got %q", printer.String())
+			}
+		})
+	}
+
+	partialCases := []struct {
+		name     string
+		identity map[byte]string
+	}{
+		{"printer reporting no model name uses fallback profile", map[byte]string{0x01: "\x20", 0x02: "\x02"}},
+		{"printer replying to nothing uses fallback profile", nil},
+	}
+
+	for _, testCase := range partialCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			printer := newFakePrinter()
+			printer.identity = testCase.identity
+
+			client, err := NewDetectedClient(printer, DefaultProfileRegistry(), 10*time.Millisecond)
+
+			if err != nil {
+				t.Fatalf("NewDetectedClient returned error: %v", err)
+			}
+
+			if client.profile != (Generic58mm{}) {
+				t.Errorf("NewDetectedClient chose %T, wanted Generic58mm", client.profile)
+			}
+		})
+	}
+
+	registryCases := []struct {
+		name     string
+		registry *ProfileRegistry
+	}{
+		{"nil registry returns config error and writes nothing", nil},
+		{"registry without fallback returns config error and writes nothing", NewProfileRegistry(nil)},
+	}
+
+	for _, testCase := range registryCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			printer := newFakePrinter()
+
+			_, err := NewDetectedClient(printer, testCase.registry, time.Second)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("NewDetectedClient did not return config error, got %v", err)
+			}
+
+			if printer.Len() != 0 {
+				t.Errorf("NewDetectedClient wrote bytes despite error, got %q", printer.String())
+			}
+		})
+	}
+
+	t.Run("detected profile tells messages apart", func(t *testing.T) {
+		printer := newFakePrinter()
+		printer.identity = identity("TM-T88VII")
+		client, _ := NewDetectedClient(printer, DefaultProfileRegistry(), time.Second)
+
+		err := client.Commit(time.Second)
+
+		if err != nil {
+			t.Errorf("Commit returned error: %v", err)
+		}
+	})
+}
+
+func TestClient_OpenDrawer(t *testing.T) {
+	t.Run("open drawer writes only the pulse", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.OpenDrawer(2, 100*time.Millisecond, 200*time.Millisecond)
+
+		if err != nil {
+			t.Errorf("OpenDrawer returned error: %v", err)
+		}
+
+		if writer.String() != "\x1Bp\x00\x32\x64" {
+			t.Errorf("OpenDrawer did not write expected bytes, got %q", writer.String())
+		}
+	})
+
+	t.Run("open drawer now writes real-time pulse", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.OpenDrawerNow(5, 200*time.Millisecond)
+
+		if err != nil {
+			t.Errorf("OpenDrawerNow returned error: %v", err)
+		}
+
+		if writer.String() != "\x10\x14\x01\x01\x02" {
+			t.Errorf("OpenDrawerNow did not write expected bytes, got %q", writer.String())
+		}
+	})
+
+	t.Run("invalid pin writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.OpenDrawer(3, 100*time.Millisecond, 200*time.Millisecond)
+
+		if !errors.Is(err, ErrInvalidConfig) || writer.Len() != 0 {
+			t.Errorf("OpenDrawer did not return config error without writing, got %v and %q", err, writer.String())
+		}
+	})
+
+	t.Run("profile without cash drawer returns unsupported error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, Generic58mm{})
+
+		err := client.OpenDrawer(2, 100*time.Millisecond, 200*time.Millisecond)
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("OpenDrawer did not return unsupported error, got %v", err)
+		}
+
+		err = client.OpenDrawerNow(2, 100*time.Millisecond)
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("OpenDrawerNow did not return unsupported error, got %v", err)
+		}
+	})
+
+	cases := []struct {
+		name  string
+		reply byte
+		want  bool
+	}{
+		{"drawer is open when pin 3 is low", 0x12, true},
+		{"drawer is closed when pin 3 is high", 0x16, false},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			printer := newFakePrinter(testCase.reply)
+			client, _ := NewBidirectionalClient(printer, EpsonTMT20III{})
+			printer.Reset()
+
+			got, err := client.DrawerOpen(time.Second)
+
+			if err != nil {
+				t.Fatalf("DrawerOpen returned error: %v", err)
+			}
+
+			if got != testCase.want {
+				t.Errorf("DrawerOpen returned %v, wanted %v", got, testCase.want)
+			}
+
+			if printer.String() != "\x10\x04\x01" {
+				t.Errorf("DrawerOpen did not write expected bytes, got %q", printer.String())
+			}
+		})
+	}
+
+	t.Run("drawer sensor needs a reader", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		_, err := client.DrawerOpen(time.Second)
+
+		if !errors.Is(err, ErrNotBidirectional) {
+			t.Errorf("DrawerOpen did not return not bidirectional error, got %v", err)
+		}
+	})
+}
+
+type reverseFeedProfile struct {
+	EpsonTMT20III
+}
+
+func (reverseFeedProfile) ReverseFeedLinesCommand(lines int) (string, error) {
+	return string([]byte{'\x1B', 'e', byte(lines)}), nil
+}
+
+func TestClient_FeedAndSpacing(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile Profile
+		call    func(*Client) error
+		want    string
+	}{
+		{"feed lines", EpsonTMT20III{}, func(client *Client) error { return client.Feed(3) }, "\x1Bd\x03"},
+		{"feed dots", EpsonTMT20III{}, func(client *Client) error { return client.FeedDots(24) }, "\x1BJ\x18"},
+		{"feed dots in half-dot motion units", EpsonTMT88VII{}, func(client *Client) error { return client.FeedDots(24) }, "\x1BJ\x30"},
+		{"reverse feed", reverseFeedProfile{}, func(client *Client) error { return client.ReverseFeed(2) }, "\x1Be\x02"},
+		{"set line spacing", EpsonTMT20III{}, func(client *Client) error { return client.SetLineSpacing(24) }, "\x1B3\x18"},
+		{"reset line spacing", EpsonTMT20III{}, func(client *Client) error { return client.ResetLineSpacing() }, "\x1B2"},
+		{"set character spacing", EpsonTMT20III{}, func(client *Client) error { return client.SetCharSpacing(2) }, "\x1B \x02"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var writer bytes.Buffer
+			client, _ := NewClient(&writer, testCase.profile)
+			writer.Reset()
+
+			err := testCase.call(&client)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if writer.String() != testCase.want {
+				t.Errorf("did not write expected bytes: wanted %q, got %q", testCase.want, writer.String())
+			}
+		})
+	}
+
+	t.Run("invalid feed writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.Feed(300)
+
+		if !errors.Is(err, ErrInvalidConfig) || writer.Len() != 0 {
+			t.Errorf("Feed did not return config error without writing, got %v and %q", err, writer.String())
+		}
+	})
+
+	t.Run("profile without reverse feed returns unsupported error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+
+		err := client.ReverseFeed(1)
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("ReverseFeed did not return unsupported error, got %v", err)
+		}
+	})
+}
+
+func TestClient_Positioning(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile Profile
+		call    func(*Client) error
+		want    string
+	}{
+		{"left margin in dots", EpsonTMT20III{}, func(client *Client) error { return client.SetLeftMargin(Dots(40)) }, "\x1DL\x28\x00"},
+		{"left margin in millimetres", EpsonTMT88VII{}, func(client *Client) error { return client.SetLeftMargin(Millimetres(5)) }, "\x1DL\x23\x00"},
+		{"print area width in millimetres", Generic58mm{}, func(client *Client) error { return client.SetPrintAreaWidth(Millimetres(48)) }, "\x1DW\x80\x01"},
+		{"move to column", EpsonTMT20III{}, func(client *Client) error { return client.MoveTo(Dots(400)) }, "\x1B$\x90\x01"},
+		{"move back", EpsonTMT20III{}, func(client *Client) error { return client.MoveBy(Millimetres(-1)) }, "\x1B\\\xF8\xFF"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var writer bytes.Buffer
+			client, _ := NewClient(&writer, testCase.profile)
+			writer.Reset()
+
+			err := testCase.call(&client)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if writer.String() != testCase.want {
+				t.Errorf("did not write expected bytes: wanted %q, got %q", testCase.want, writer.String())
+			}
+		})
+	}
+
+	t.Run("invalid position writes nothing", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, EpsonTMT20III{})
+		writer.Reset()
+
+		err := client.MoveTo(Dots(-1))
+
+		if !errors.Is(err, ErrInvalidConfig) || writer.Len() != 0 {
+			t.Errorf("MoveTo did not return config error without writing, got %v and %q", err, writer.String())
+		}
+	})
+
+	t.Run("profile without positioning returns unsupported error", func(t *testing.T) {
+		var writer bytes.Buffer
+		client, _ := NewClient(&writer, basicProfile{EpsonTMT20III{}})
+
+		err := client.MoveTo(Dots(400))
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("MoveTo did not return unsupported error, got %v", err)
+		}
+	})
+}
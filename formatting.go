@@ -1,7 +1,5 @@
 package escpos
 
-import "fmt"
-
 type FormatConfig struct {
 	justification string
 	emphasis      bool
@@ -9,24 +7,77 @@ type FormatConfig struct {
 	underline     string
 	charWidth     uint8
 	charHeight    uint8
+	reverse       bool
+	doubleStrike  bool
+	upsideDown    bool
+	rotate        bool
+	smoothing     bool
 }
 
-func (fmtCfg FormatConfig) apply(client *Client, profile Profile) {
-	commands := []func(*FormatConfig) (string, error){
-		profile.FontCommand,
-		profile.JustificationCommand,
-		profile.EmphasisCommand,
-		profile.UnderlineCommand,
-		profile.CharSizeCommand,
-	}
+// commands builds every formatting command for the config, returning
+// the first error so a partially applied format is never written. The
+// optional styles are only set when the config enables them, so they
+// are not needed from the profile otherwise.
+func (fmtCfg FormatConfig) commands(profile Profile) (string, error) {
+	var buf commandBuffer
+	buf.add(profile.FontCommand(&fmtCfg))
+	buf.add(profile.JustificationCommand(&fmtCfg))
+	buf.add(profile.EmphasisCommand(&fmtCfg))
+	buf.add(profile.UnderlineCommand(&fmtCfg))
+	buf.add(profile.CharSizeCommand(&fmtCfg))
+	fmtCfg.addStyles(&buf, profile, &fmtCfg)
+	return buf.result()
+}
 
-	for _, command := range commands {
-		value, err := command(&fmtCfg)
-		if err != nil {
-			fmt.Printf("failed building command: %v\n", err)
-			return
+// resetCommands builds the commands restoring the default format after
+// text written with the config, including turning off the optional
+// styles it enabled.
+func (fmtCfg FormatConfig) resetCommands(profile Profile) (string, error) {
+	defaults := DefaultFormatConfig()
+	var buf commandBuffer
+	buf.add(defaults.commands(profile))
+	defaults.addStyles(&buf, profile, &fmtCfg)
+	return buf.result()
+}
+
+// addStyles adds the commands setting each optional style which enabled
+// has turned on to its value in the config, or an UnsupportedError if
+// the profile lacks the capability.
+func (fmtCfg FormatConfig) addStyles(buf *commandBuffer, profile Profile, enabled *FormatConfig) {
+	if enabled.reverse {
+		if style, ok := profile.(Reverse); ok {
+			buf.add(style.ReverseCommand(&fmtCfg))
+		} else {
+			buf.add("", unsupported("reverse"))
+		}
+	}
+	if enabled.doubleStrike {
+		if style, ok := profile.(DoubleStrike); ok {
+			buf.add(style.DoubleStrikeCommand(&fmtCfg))
+		} else {
+			buf.add("", unsupported("double-strike"))
+		}
+	}
+	if enabled.upsideDown {
+		if style, ok := profile.(UpsideDown); ok {
+			buf.add(style.UpsideDownCommand(&fmtCfg))
+		} else {
+			buf.add("", unsupported("upside-down"))
+		}
+	}
+	if enabled.rotate {
+		if style, ok := profile.(Rotation); ok {
+			buf.add(style.RotationCommand(&fmtCfg))
+		} else {
+			buf.add("", unsupported("rotation"))
+		}
+	}
+	if enabled.smoothing {
+		if style, ok := profile.(Smoothing); ok {
+			buf.add(style.SmoothingCommand(&fmtCfg))
+		} else {
+			buf.add("", unsupported("smoothing"))
 		}
-		client.writeString(value)
 	}
 }
 
@@ -78,3 +129,41 @@ func (fmtCfg FormatConfig) CharSize(width uint8, height uint8) FormatConfig {
 	fmtCfg.charHeight = height
 	return fmtCfg
 }
+
+// Reverse sets whether text is printed white on black. The default is
+// false.
+func (fmtCfg FormatConfig) Reverse(enabled bool) FormatConfig {
+	fmtCfg.reverse = enabled
+	return fmtCfg
+}
+
+// DoubleStrike sets whether text is printed twice over, which looks much
+// like emphasis on most printers. The default is false.
+func (fmtCfg FormatConfig) DoubleStrike(enabled bool) FormatConfig {
+	fmtCfg.doubleStrike = enabled
+	return fmtCfg
+}
+
+// UpsideDown sets whether lines are printed rotated by 180 degrees, for
+// reading from the other side of the printer. It only takes effect for
+// lines started after it is set and can only be turned off again at the
+// start of a line, so text written upside-down must end with a newline.
+// The default is false.
+func (fmtCfg FormatConfig) UpsideDown(enabled bool) FormatConfig {
+	fmtCfg.upsideDown = enabled
+	return fmtCfg
+}
+
+// Rotate sets whether each character is printed rotated 90 degrees
+// clockwise. The default is false.
+func (fmtCfg FormatConfig) Rotate(enabled bool) FormatConfig {
+	fmtCfg.rotate = enabled
+	return fmtCfg
+}
+
+// Smoothing sets whether the edges of enlarged characters are smoothed.
+// The default is false.
+func (fmtCfg FormatConfig) Smoothing(enabled bool) FormatConfig {
+	fmtCfg.smoothing = enabled
+	return fmtCfg
+}
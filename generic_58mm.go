@@ -0,0 +1,174 @@
+package escpos
+
+// Generic58mm implements the ESC/POS commands common to inexpensive 58 mm
+// printers which follow Epson's text, raster image and identification
+// commands but have no native 2D symbol or barcode support. The Client
+// renders QR codes and barcodes itself for these printers and prints
+// them as raster images.
+type Generic58mm struct {
+}
+
+func (Generic58mm) InitCommand() (string, error) {
+	return EpsonTMT20III{}.InitCommand()
+}
+
+func (Generic58mm) CutCommand(cfg *CutConfig) (string, error) {
+	return EpsonTMT20III{}.CutCommand(cfg)
+}
+
+func (Generic58mm) CutterDistance() int {
+	// Most of these printers have their cutter about 12 mm above the
+	// print head.
+	return 96
+}
+
+func (Generic58mm) FeedsToCutter() bool {
+	return false
+}
+
+func (Generic58mm) EndCommand() (string, error) {
+	return "", nil
+}
+
+func (Generic58mm) FontCommand(fmtCfg *FormatConfig) (string, error) {
+	switch fmtCfg.font {
+	case "A":
+		return "\x1BM0", nil
+	case "B":
+		return "\x1BM1", nil
+	default:
+		return "", invalidConfig("invalid font option in FormatConfig")
+	}
+}
+
+func (Generic58mm) JustificationCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.JustificationCommand(fmtCfg)
+}
+
+func (Generic58mm) EmphasisCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.EmphasisCommand(fmtCfg)
+}
+
+func (Generic58mm) UnderlineCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.UnderlineCommand(fmtCfg)
+}
+
+func (Generic58mm) CharSizeCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.CharSizeCommand(fmtCfg)
+}
+
+func (Generic58mm) ReverseCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.ReverseCommand(fmtCfg)
+}
+
+func (Generic58mm) DoubleStrikeCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.DoubleStrikeCommand(fmtCfg)
+}
+
+func (Generic58mm) UpsideDownCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.UpsideDownCommand(fmtCfg)
+}
+
+func (Generic58mm) RotationCommand(fmtCfg *FormatConfig) (string, error) {
+	return EpsonTMT20III{}.RotationCommand(fmtCfg)
+}
+
+func (Generic58mm) SelectQrCodeModelCommand(*QrCodeConfig) (string, error) {
+	return "", unsupported("QR code")
+}
+
+func (Generic58mm) SetQrCodeSizeCommand(*QrCodeConfig) (string, error) {
+	return "", unsupported("QR code")
+}
+
+func (Generic58mm) SelectQrCodeErrorCorrectionLevelCommand(*QrCodeConfig) (string, error) {
+	return "", unsupported("QR code")
+}
+
+func (Generic58mm) StoreQrCodeDataCommand(string) (string, error) {
+	return "", unsupported("QR code")
+}
+
+func (Generic58mm) PrintQrCodeDataCommand() (string, error) {
+	return "", unsupported("QR code")
+}
+
+func (Generic58mm) CodeTables() []string {
+	return []string{"CP437", "CP858", "CP850", "WPC1252", "CP852", "CP866"}
+}
+
+func (profile Generic58mm) SelectCodeTableCommand(table string) (string, error) {
+	for _, candidate := range profile.CodeTables() {
+		if candidate == table {
+			return EpsonTMT20III{}.SelectCodeTableCommand(table)
+		}
+	}
+	return "", invalidConfig("invalid code table: %v", table)
+}
+
+func (Generic58mm) PrintableWidth() int {
+	return 384
+}
+
+func (Generic58mm) DotsPerInch() int {
+	return 203
+}
+
+func (Generic58mm) RasterImageCommand(bitmap *Bitmap) (string, error) {
+	if bitmap.Height > 255 {
+		return "", invalidConfig("invalid raster image size: width %v, height %v", bitmap.Width, bitmap.Height)
+	}
+	return EpsonTMT20III{}.RasterImageCommand(bitmap)
+}
+
+func (Generic58mm) MaxRasterBandHeight() int {
+	return 255
+}
+
+func (Generic58mm) IdentificationCommand(kind string) (string, error) {
+	return EpsonTMT20III{}.IdentificationCommand(kind)
+}
+
+func (Generic58mm) IdentificationBlock(first byte) bool {
+	return EpsonTMT20III{}.IdentificationBlock(first)
+}
+
+func (Generic58mm) DecodeIdentification(kind string, reply []byte, info *PrinterInfo) error {
+	return EpsonTMT20III{}.DecodeIdentification(kind, reply, info)
+}
+
+func (Generic58mm) FeedLinesCommand(lines int) (string, error) {
+	return EpsonTMT20III{}.FeedLinesCommand(lines)
+}
+
+func (Generic58mm) FeedDotsCommand(dots int) (string, error) {
+	return EpsonTMT20III{}.FeedDotsCommand(dots)
+}
+
+func (Generic58mm) LineSpacingCommand(dots int) (string, error) {
+	return EpsonTMT20III{}.LineSpacingCommand(dots)
+}
+
+func (Generic58mm) DefaultLineSpacingCommand() (string, error) {
+	return EpsonTMT20III{}.DefaultLineSpacingCommand()
+}
+
+func (Generic58mm) CharSpacingCommand(dots int) (string, error) {
+	return EpsonTMT20III{}.CharSpacingCommand(dots)
+}
+
+func (Generic58mm) LeftMarginCommand(dots int) (string, error) {
+	return EpsonTMT20III{}.LeftMarginCommand(dots)
+}
+
+func (Generic58mm) PrintAreaWidthCommand(dots int) (string, error) {
+	return EpsonTMT20III{}.PrintAreaWidthCommand(dots)
+}
+
+func (Generic58mm) AbsolutePositionCommand(dots int) (string, error) {
+	return EpsonTMT20III{}.AbsolutePositionCommand(dots)
+}
+
+func (Generic58mm) RelativePositionCommand(dots int) (string, error) {
+	return EpsonTMT20III{}.RelativePositionCommand(dots)
+}
@@ -0,0 +1,68 @@
+package escpos
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestGeneric58mm_QrCodeCommands(t *testing.T) {
+	var profile Profile = Generic58mm{}
+	cfg := DefaultQrCodeConfig()
+
+	commandFuncs := map[string]func() (string, error){
+		"SelectQrCodeModelCommand":                func() (string, error) { return profile.SelectQrCodeModelCommand(&cfg) },
+		"SetQrCodeSizeCommand":                    func() (string, error) { return profile.SetQrCodeSizeCommand(&cfg) },
+		"SelectQrCodeErrorCorrectionLevelCommand": func() (string, error) { return profile.SelectQrCodeErrorCorrectionLevelCommand(&cfg) },
+		"StoreQrCodeDataCommand":                  func() (string, error) { return profile.StoreQrCodeDataCommand("abc") },
+		"PrintQrCodeDataCommand":                  profile.PrintQrCodeDataCommand,
+	}
+
+	for name, commandFunc := range commandFuncs {
+		t.Run(name+" returns unsupported error", func(t *testing.T) {
+			got, err := commandFunc()
+
+			if got != "" || !errors.Is(err, ErrUnsupported) {
+				t.Errorf("%v did not return unsupported error, got %q, %v", name, got, err)
+			}
+		})
+	}
+}
+
+func TestGeneric58mm_FontCommand(t *testing.T) {
+	var profile Font = Generic58mm{}
+
+	t.Run("font B returns correct value", func(t *testing.T) {
+		got, err := profile.FontCommand(&FormatConfig{font: "B"})
+
+		if err != nil {
+			t.Errorf("err was not nil")
+		}
+
+		want := []byte{'\x1B', 'M', '1'}
+
+		if !bytes.Equal([]byte(got), want) {
+			t.Errorf("FontCommand did not return expected bytes: wanted %v, got %v", want, []byte(got))
+		}
+	})
+
+	t.Run("font C returns error", func(t *testing.T) {
+		got, err := profile.FontCommand(&FormatConfig{font: "C"})
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
+
+func TestGeneric58mm_RasterImageCommand(t *testing.T) {
+	var profile RasterImage = Generic58mm{}
+
+	t.Run("raster image taller than band height returns error", func(t *testing.T) {
+		got, err := profile.RasterImageCommand(NewBitmap(8, profile.MaxRasterBandHeight()+1))
+
+		if got != "" || err == nil {
+			t.Errorf("returned command was not nil, expected empty string and error")
+		}
+	})
+}
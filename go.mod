@@ -1,3 +1,8 @@
 module github.com/reeceaw/escpos
 
 go 1.25
+
+require (
+	golang.org/x/image v0.33.0
+	golang.org/x/text v0.31.0
+)
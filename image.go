@@ -0,0 +1,218 @@
+package escpos
+
+import (
+	"image"
+	"image/color"
+)
+
+type ImageConfig struct {
+	width         int
+	justification string
+	gamma         float64
+	contrast      float64
+	ditherer      Ditherer
+}
+
+// DefaultImageConfig creates an ImageConfig containing sensible
+// default values for image printing.
+func DefaultImageConfig() ImageConfig {
+	return ImageConfig{
+		width:         0,
+		justification: "center",
+		gamma:         1,
+		contrast:      1,
+		ditherer:      Threshold{Level: 128},
+	}
+}
+
+// Width sets the width in dots the image is scaled to, keeping its
+// aspect ratio. The default of 0 keeps the image's own width. Images
+// wider than the printable width of the profile are always scaled down
+// to fit.
+func (cfg ImageConfig) Width(width int) ImageConfig {
+	cfg.width = width
+	return cfg
+}
+
+// Justify sets the image justification. The default is center.
+func (cfg ImageConfig) Justify(justification string) ImageConfig {
+	cfg.justification = justification
+	return cfg
+}
+
+// Gamma sets the gamma correction applied before conversion to 1-bit.
+// Values above 1 lighten mid-tones and values below 1 darken them. The
+// default is 1, which leaves the image unchanged.
+func (cfg ImageConfig) Gamma(gamma float64) ImageConfig {
+	cfg.gamma = gamma
+	return cfg
+}
+
+// Contrast sets the contrast multiplier applied before conversion to
+// 1-bit. Values above 1 increase contrast and values below 1 reduce it.
+// The default is 1, which leaves the image unchanged.
+func (cfg ImageConfig) Contrast(contrast float64) ImageConfig {
+	cfg.contrast = contrast
+	return cfg
+}
+
+// Dither sets the Ditherer used to convert the image to 1-bit. The
+// default is a Threshold at mid-grey, which suits logos. Photographs
+// print better with FloydSteinberg, Atkinson or Bayer.
+func (cfg ImageConfig) Dither(ditherer Ditherer) ImageConfig {
+	cfg.ditherer = ditherer
+	return cfg
+}
+
+// Bitmap is a 1-bit image with each row packed into bytes, most
+// significant bit first, where a set bit is a printed (black) dot. This
+// is the layout used by ESC/POS raster bit image commands. Bitmap
+// implements image.Image so converted images can be previewed.
+type Bitmap struct {
+	Width  int
+	Height int
+	Pix    []byte
+}
+
+// NewBitmap creates a blank (white) Bitmap of the given size.
+func NewBitmap(width int, height int) *Bitmap {
+	bitmap := &Bitmap{Width: width, Height: height}
+	bitmap.Pix = make([]byte, bitmap.Stride()*height)
+	return bitmap
+}
+
+// Stride returns the number of bytes used by each row.
+func (bitmap *Bitmap) Stride() int {
+	return (bitmap.Width + 7) / 8
+}
+
+// Black reports whether the dot at x, y is printed.
+func (bitmap *Bitmap) Black(x int, y int) bool {
+	if x < 0 || y < 0 || x >= bitmap.Width || y >= bitmap.Height {
+		return false
+	}
+	return bitmap.Pix[y*bitmap.Stride()+x/8]&(0x80>>(x%8)) != 0
+}
+
+// SetBlack sets whether the dot at x, y is printed.
+func (bitmap *Bitmap) SetBlack(x int, y int, black bool) {
+	if x < 0 || y < 0 || x >= bitmap.Width || y >= bitmap.Height {
+		return
+	}
+	if black {
+		bitmap.Pix[y*bitmap.Stride()+x/8] |= 0x80 >> (x % 8)
+	} else {
+		bitmap.Pix[y*bitmap.Stride()+x/8] &^= 0x80 >> (x % 8)
+	}
+}
+
+func (bitmap *Bitmap) ColorModel() color.Model {
+	return color.GrayModel
+}
+
+func (bitmap *Bitmap) Bounds() image.Rectangle {
+	return image.Rect(0, 0, bitmap.Width, bitmap.Height)
+}
+
+func (bitmap *Bitmap) At(x int, y int) color.Color {
+	if bitmap.Black(x, y) {
+		return color.Gray{Y: 0}
+	}
+	return color.Gray{Y: 255}
+}
+
+// band returns the rows from y0 up to y1 as a Bitmap sharing the same
+// pixel data.
+func (bitmap *Bitmap) band(y0 int, y1 int) *Bitmap {
+	stride := bitmap.Stride()
+	return &Bitmap{Width: bitmap.Width, Height: y1 - y0, Pix: bitmap.Pix[y0*stride : y1*stride]}
+}
+
+// toGray converts any image into greyscale, compositing transparent
+// pixels onto white paper.
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			// Colours are alpha-premultiplied, so adding the uncovered
+			// fraction of white composites the pixel onto paper.
+			white := 0xFFFF - a
+			lum := (19595*(r+white) + 38470*(g+white) + 7471*(b+white) + 1<<15) >> 24
+			gray.Pix[y*gray.Stride+x] = uint8(lum)
+		}
+	}
+
+	return gray
+}
+
+// resizeGray scales the image to the given size, averaging the source
+// pixels covered by each destination pixel.
+func resizeGray(src *image.Gray, width int, height int) *image.Gray {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if srcWidth == width && srcHeight == height {
+		return src
+	}
+
+	dst := image.NewGray(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			sum := 0
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					sum += int(src.Pix[sy*src.Stride+sx])
+				}
+			}
+			dst.Pix[y*dst.Stride+x] = uint8(sum / ((x1 - x0) * (y1 - y0)))
+		}
+	}
+
+	return dst
+}
+
+// scaledSize returns the size of the image once scaled to the given
+// width, keeping its aspect ratio.
+func scaledSize(bounds image.Rectangle, width int) (int, int) {
+	if width <= 0 || width == bounds.Dx() {
+		return bounds.Dx(), bounds.Dy()
+	}
+	height := max((bounds.Dy()*width+bounds.Dx()/2)/bounds.Dx(), 1)
+	return width, height
+}
+
+// tileBitmaps lays the bitmaps out in rows of the given number of
+// columns, on a grid of cells the size of the largest bitmap separated
+// by gap dots, and returns the result as a single Bitmap.
+func tileBitmaps(bitmaps []*Bitmap, columns int, gap int) *Bitmap {
+	cellWidth, cellHeight := 0, 0
+	for _, bitmap := range bitmaps {
+		cellWidth = max(cellWidth, bitmap.Width)
+		cellHeight = max(cellHeight, bitmap.Height)
+	}
+
+	columns = min(columns, len(bitmaps))
+	rows := (len(bitmaps) + columns - 1) / columns
+	tiled := NewBitmap(columns*(cellWidth+gap)-gap, rows*(cellHeight+gap)-gap)
+
+	for i, bitmap := range bitmaps {
+		x0, y0 := (i%columns)*(cellWidth+gap), (i/columns)*(cellHeight+gap)
+		for y := 0; y < bitmap.Height; y++ {
+			for x := 0; x < bitmap.Width; x++ {
+				if bitmap.Black(x, y) {
+					tiled.SetBlack(x0+x, y0+y, true)
+				}
+			}
+		}
+	}
+
+	return tiled
+}
@@ -0,0 +1,35 @@
+package escpos
+
+import "math"
+
+// Length is a distance across the paper, in dots or millimetres.
+// Lengths in millimetres are converted to dots at the resolution the
+// profile declares.
+type Length struct {
+	value  float64
+	metric bool
+}
+
+// Dots creates a Length of the given number of dots.
+func Dots(dots int) Length {
+	return Length{value: float64(dots)}
+}
+
+// Millimetres creates a Length of the given number of millimetres.
+func Millimetres(mm float64) Length {
+	return Length{value: mm, metric: true}
+}
+
+// dots returns the length in dots, rounded to the nearest dot, for a
+// printer with the resolution of the profile.
+func (length Length) dots(profile Profile) (int, error) {
+	if !length.metric {
+		return int(length.value), nil
+	}
+
+	resolution, ok := profile.(Resolution)
+	if !ok {
+		return 0, unsupported("resolution")
+	}
+	return int(math.Round(length.value * float64(resolution.DotsPerInch()) / 25.4)), nil
+}
@@ -0,0 +1,58 @@
+package escpos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	cases := []struct {
+		name    string
+		length  Length
+		profile Profile
+		want    int
+	}{
+		{"dots are unchanged", Dots(400), EpsonTMT20III{}, 400},
+		{"negative dots are unchanged", Dots(-24), EpsonTMT88VII{}, -24},
+		{"millimetres at 203 dpi", Millimetres(10), EpsonTMT20III{}, 80},
+		{"millimetres at 180 dpi", Millimetres(10), EpsonTMT88VII{}, 71},
+		{"negative millimetres", Millimetres(-5), Generic58mm{}, -40},
+		{"zero length", Length{}, EpsonTMT20III{}, 0},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := testCase.length.dots(testCase.profile)
+
+			if err != nil {
+				t.Errorf("err was not nil")
+			}
+
+			if got != testCase.want {
+				t.Errorf("dots returned %v, wanted %v", got, testCase.want)
+			}
+		})
+	}
+
+	t.Run("millimetres without resolution returns unsupported error", func(t *testing.T) {
+		_, err := Millimetres(10).dots(basicProfile{EpsonTMT20III{}})
+
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("dots did not return unsupported error, got %v", err)
+		}
+	})
+
+	t.Run("dots without resolution are unchanged", func(t *testing.T) {
+		got, err := Dots(10).dots(basicProfile{EpsonTMT20III{}})
+
+		if err != nil || got != 10 {
+			t.Errorf("dots returned %v and %v, wanted 10", got, err)
+		}
+	})
+}
+
+// basicProfile has only the commands every Profile has, and none of the
+// optional capabilities of the profile it wraps.
+type basicProfile struct {
+	Profile
+}
@@ -0,0 +1,29 @@
+package escpos
+
+type MaxiCodeConfig struct {
+	mode          uint8
+	justification string
+}
+
+// DefaultMaxiCodeConfig creates a MaxiCodeConfig containing sensible
+// default values for MaxiCode printing.
+func DefaultMaxiCodeConfig() MaxiCodeConfig {
+	return MaxiCodeConfig{
+		mode:          2,
+		justification: "center",
+	}
+}
+
+// Mode sets the MaxiCode mode from 2 to 6. Modes 2 and 3 carry a
+// structured carrier message, 4 is standard symbol, 5 is full error
+// correction and 6 is for reader programming. The default is mode 2.
+func (cfg MaxiCodeConfig) Mode(mode uint8) MaxiCodeConfig {
+	cfg.mode = mode
+	return cfg
+}
+
+// Justify sets the MaxiCode symbol justification. The default is center.
+func (cfg MaxiCodeConfig) Justify(justification string) MaxiCodeConfig {
+	cfg.justification = justification
+	return cfg
+}
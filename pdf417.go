@@ -0,0 +1,84 @@
+package escpos
+
+type Pdf417Config struct {
+	columns         uint8
+	rows            uint8
+	moduleWidth     uint8
+	rowHeight       uint8
+	errorCorrection string
+	errorLevel      uint8
+	truncated       bool
+	justification   string
+}
+
+// DefaultPdf417Config creates a Pdf417Config containing sensible
+// default values for PDF417 printing.
+func DefaultPdf417Config() Pdf417Config {
+	return Pdf417Config{
+		columns:         0,
+		rows:            0,
+		moduleWidth:     3,
+		rowHeight:       3,
+		errorCorrection: "ratio",
+		errorLevel:      1,
+		truncated:       false,
+		justification:   "center",
+	}
+}
+
+// Columns sets the number of data columns. Most printers support 1 to
+// 30. The default of 0 lets the printer choose.
+func (cfg Pdf417Config) Columns(columns uint8) Pdf417Config {
+	cfg.columns = columns
+	return cfg
+}
+
+// Rows sets the number of rows. Most printers support 3 to 90. The
+// default of 0 lets the printer choose.
+func (cfg Pdf417Config) Rows(rows uint8) Pdf417Config {
+	cfg.rows = rows
+	return cfg
+}
+
+// ModuleWidth sets the width in dots of a module. Most printers support
+// 2 to 8. The default is 3.
+func (cfg Pdf417Config) ModuleWidth(width uint8) Pdf417Config {
+	cfg.moduleWidth = width
+	return cfg
+}
+
+// RowHeight sets the height of each row as a multiple of the module
+// width. Most printers support 2 to 8. The default is 3.
+func (cfg Pdf417Config) RowHeight(height uint8) Pdf417Config {
+	cfg.rowHeight = height
+	return cfg
+}
+
+// ErrorCorrectionLevel sets a fixed error correction level from 0 to 8.
+func (cfg Pdf417Config) ErrorCorrectionLevel(level uint8) Pdf417Config {
+	cfg.errorCorrection = "level"
+	cfg.errorLevel = level
+	return cfg
+}
+
+// ErrorCorrectionRatio sets the error correction level as a ratio of
+// the data, in steps of 10% from 1 (10%) to 40 (400%). The default is 1.
+func (cfg Pdf417Config) ErrorCorrectionRatio(ratio uint8) Pdf417Config {
+	cfg.errorCorrection = "ratio"
+	cfg.errorLevel = ratio
+	return cfg
+}
+
+// Truncated sets whether the truncated (compact) PDF417 symbol is
+// printed, which omits the right row indicators to save width. The
+// default is false.
+func (cfg Pdf417Config) Truncated(enabled bool) Pdf417Config {
+	cfg.truncated = enabled
+	return cfg
+}
+
+// Justify sets the PDF417 symbol justification. The default is center.
+func (cfg Pdf417Config) Justify(justification string) Pdf417Config {
+	cfg.justification = justification
+	return cfg
+}
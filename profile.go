@@ -1,5 +1,7 @@
 package escpos
 
+import "time"
+
 // Init is used in initialising or resetting the printer.
 type Init interface {
 	// InitCommand should return the printer-specific command to
@@ -10,8 +12,8 @@ type Init interface {
 // Cut allows for cutting the printer paper.
 type Cut interface {
 	// CutCommand should return the printer-specific command to cut the
-	// printer paper.
-	CutCommand() (string, error)
+	// printer paper based on the values in the CutConfig.
+	CutCommand(*CutConfig) (string, error)
 }
 
 type End interface {
@@ -47,6 +49,42 @@ type Underline interface {
 	UnderlineCommand(*FormatConfig) (string, error)
 }
 
+// Reverse allows for printing text white on black.
+type Reverse interface {
+	// ReverseCommand should return the printer-specific command to set
+	// reverse printing based on the value found in the FormatConfig.
+	ReverseCommand(*FormatConfig) (string, error)
+}
+
+// DoubleStrike allows for printing text twice over.
+type DoubleStrike interface {
+	// DoubleStrikeCommand should return the printer-specific command to
+	// set double-strike printing based on the value found in the
+	// FormatConfig.
+	DoubleStrikeCommand(*FormatConfig) (string, error)
+}
+
+// UpsideDown allows for printing lines rotated by 180 degrees.
+type UpsideDown interface {
+	// UpsideDownCommand should return the printer-specific command to set
+	// upside-down printing based on the value found in the FormatConfig.
+	UpsideDownCommand(*FormatConfig) (string, error)
+}
+
+// Rotation allows for printing characters rotated 90 degrees clockwise.
+type Rotation interface {
+	// RotationCommand should return the printer-specific command to set
+	// character rotation based on the value found in the FormatConfig.
+	RotationCommand(*FormatConfig) (string, error)
+}
+
+// Smoothing allows for smoothing the edges of enlarged characters.
+type Smoothing interface {
+	// SmoothingCommand should return the printer-specific command to set
+	// smoothing based on the value found in the FormatConfig.
+	SmoothingCommand(*FormatConfig) (string, error)
+}
+
 // CharSize allows for the scaling of character size.
 type CharSize interface {
 	// CharSizeCommand should return the printer-specific command to set
@@ -87,7 +125,10 @@ type StoreQrCodeData interface {
 // PrintQrCodeData prints the QR code.
 type PrintQrCodeData interface {
 	// PrintQrCodeDataCommand should return the printer-specific command
-	// to print the QR code.
+	// to print the QR code. Profiles for printers without native QR
+	// codes should return an UnsupportedError from this and the other QR
+	// code commands, and the Client will render QR codes as raster
+	// images instead.
 	PrintQrCodeDataCommand() (string, error)
 }
 
@@ -109,3 +150,533 @@ type Profile interface {
 	StoreQrCodeData
 	PrintQrCodeData
 }
+
+// The interfaces below are optional capabilities which a Profile may
+// also implement. Client methods relying on a capability the profile
+// lacks return an UnsupportedError.
+
+// PrintArea describes the printable area of the printer.
+type PrintArea interface {
+	// PrintableWidth should return the width in dots that the printer
+	// can print across.
+	PrintableWidth() int
+}
+
+// Resolution describes the resolution of the printer, so lengths in
+// millimetres can be converted to dots.
+type Resolution interface {
+	// DotsPerInch should return the number of dots the printer prints
+	// per inch across the paper.
+	DotsPerInch() int
+}
+
+// RasterImage allows for the printing of raster bit images.
+type RasterImage interface {
+	// RasterImageCommand should return the printer-specific command to
+	// print the given Bitmap, which is never taller than the height
+	// returned by MaxRasterBandHeight.
+	RasterImageCommand(*Bitmap) (string, error)
+
+	// MaxRasterBandHeight should return the height in dots of the
+	// tallest Bitmap a single raster image command can print.
+	MaxRasterBandHeight() int
+}
+
+// SetBarcodeWidth sets the barcode module width.
+type SetBarcodeWidth interface {
+	// SetBarcodeWidthCommand should return the printer-specific command
+	// to set the barcode module width based on the given BarcodeConfig.
+	SetBarcodeWidthCommand(*BarcodeConfig) (string, error)
+}
+
+// SetBarcodeHeight sets the barcode height.
+type SetBarcodeHeight interface {
+	// SetBarcodeHeightCommand should return the printer-specific command
+	// to set the barcode height based on the given BarcodeConfig.
+	SetBarcodeHeightCommand(*BarcodeConfig) (string, error)
+}
+
+// SelectHriPosition selects where the barcode human readable
+// interpretation is printed.
+type SelectHriPosition interface {
+	// SelectHriPositionCommand should return the printer-specific
+	// command to set the HRI position based on the given BarcodeConfig.
+	SelectHriPositionCommand(*BarcodeConfig) (string, error)
+}
+
+// SelectHriFont selects the font of the barcode human readable
+// interpretation.
+type SelectHriFont interface {
+	// SelectHriFontCommand should return the printer-specific command to
+	// set the HRI font based on the given BarcodeConfig.
+	SelectHriFontCommand(*BarcodeConfig) (string, error)
+}
+
+// PrintBarcode prints a barcode.
+type PrintBarcode interface {
+	// PrintBarcodeCommand should return the printer-specific command to
+	// print the given data as a barcode in the symbology of the given
+	// BarcodeConfig. The data has already been validated. Returning an
+	// UnsupportedError for a symbology the printer lacks makes the Client
+	// render the barcode as a raster image instead.
+	PrintBarcodeCommand(string, *BarcodeConfig) (string, error)
+}
+
+// Barcode groups the interfaces needed to print 1D barcodes.
+type Barcode interface {
+	SetBarcodeWidth
+	SetBarcodeHeight
+	SelectHriPosition
+	SelectHriFont
+	PrintBarcode
+}
+
+// SetPdf417Columns sets the number of PDF417 data columns.
+type SetPdf417Columns interface {
+	// SetPdf417ColumnsCommand should return the printer-specific command
+	// to set the PDF417 columns based on the given Pdf417Config.
+	SetPdf417ColumnsCommand(*Pdf417Config) (string, error)
+}
+
+// SetPdf417Rows sets the number of PDF417 rows.
+type SetPdf417Rows interface {
+	// SetPdf417RowsCommand should return the printer-specific command to
+	// set the PDF417 rows based on the given Pdf417Config.
+	SetPdf417RowsCommand(*Pdf417Config) (string, error)
+}
+
+// SetPdf417ModuleWidth sets the PDF417 module width.
+type SetPdf417ModuleWidth interface {
+	// SetPdf417ModuleWidthCommand should return the printer-specific
+	// command to set the PDF417 module width based on the given
+	// Pdf417Config.
+	SetPdf417ModuleWidthCommand(*Pdf417Config) (string, error)
+}
+
+// SetPdf417RowHeight sets the PDF417 row height.
+type SetPdf417RowHeight interface {
+	// SetPdf417RowHeightCommand should return the printer-specific
+	// command to set the PDF417 row height based on the given
+	// Pdf417Config.
+	SetPdf417RowHeightCommand(*Pdf417Config) (string, error)
+}
+
+// SelectPdf417ErrorCorrectionLevel selects the PDF417 error correction
+// level.
+type SelectPdf417ErrorCorrectionLevel interface {
+	// SelectPdf417ErrorCorrectionLevelCommand should return the
+	// printer-specific command to set the PDF417 error correction level
+	// or ratio based on the given Pdf417Config.
+	SelectPdf417ErrorCorrectionLevelCommand(*Pdf417Config) (string, error)
+}
+
+// SelectPdf417Options selects between standard and truncated PDF417.
+type SelectPdf417Options interface {
+	// SelectPdf417OptionsCommand should return the printer-specific
+	// command to select standard or truncated PDF417 based on the given
+	// Pdf417Config.
+	SelectPdf417OptionsCommand(*Pdf417Config) (string, error)
+}
+
+// StorePdf417Data stores the PDF417 data in the symbol storage area.
+type StorePdf417Data interface {
+	// StorePdf417DataCommand should return the printer-specific command
+	// to store the PDF417 data in the symbol storage area.
+	StorePdf417DataCommand(string) (string, error)
+}
+
+// PrintPdf417Data prints the PDF417 symbol.
+type PrintPdf417Data interface {
+	// PrintPdf417DataCommand should return the printer-specific command
+	// to print the PDF417 symbol.
+	PrintPdf417DataCommand() (string, error)
+}
+
+// Pdf417 groups the interfaces needed to print PDF417 symbols.
+type Pdf417 interface {
+	SetPdf417Columns
+	SetPdf417Rows
+	SetPdf417ModuleWidth
+	SetPdf417RowHeight
+	SelectPdf417ErrorCorrectionLevel
+	SelectPdf417Options
+	StorePdf417Data
+	PrintPdf417Data
+}
+
+// SelectMaxiCodeMode selects the MaxiCode mode.
+type SelectMaxiCodeMode interface {
+	// SelectMaxiCodeModeCommand should return the printer-specific
+	// command to set the MaxiCode mode based on the given
+	// MaxiCodeConfig.
+	SelectMaxiCodeModeCommand(*MaxiCodeConfig) (string, error)
+}
+
+// StoreMaxiCodeData stores the MaxiCode data in the symbol storage area.
+type StoreMaxiCodeData interface {
+	// StoreMaxiCodeDataCommand should return the printer-specific
+	// command to store the MaxiCode data in the symbol storage area.
+	StoreMaxiCodeDataCommand(string) (string, error)
+}
+
+// PrintMaxiCodeData prints the MaxiCode symbol.
+type PrintMaxiCodeData interface {
+	// PrintMaxiCodeDataCommand should return the printer-specific
+	// command to print the MaxiCode symbol.
+	PrintMaxiCodeDataCommand() (string, error)
+}
+
+// MaxiCode groups the interfaces needed to print MaxiCode symbols.
+type MaxiCode interface {
+	SelectMaxiCodeMode
+	StoreMaxiCodeData
+	PrintMaxiCodeData
+}
+
+// SelectDataMatrixType selects the DataMatrix shape and size.
+type SelectDataMatrixType interface {
+	// SelectDataMatrixTypeCommand should return the printer-specific
+	// command to set the DataMatrix shape and size based on the given
+	// DataMatrixConfig.
+	SelectDataMatrixTypeCommand(*DataMatrixConfig) (string, error)
+}
+
+// SetDataMatrixModuleSize sets the DataMatrix module size.
+type SetDataMatrixModuleSize interface {
+	// SetDataMatrixModuleSizeCommand should return the printer-specific
+	// command to set the DataMatrix module size based on the given
+	// DataMatrixConfig.
+	SetDataMatrixModuleSizeCommand(*DataMatrixConfig) (string, error)
+}
+
+// StoreDataMatrixData stores the DataMatrix data in the symbol storage
+// area.
+type StoreDataMatrixData interface {
+	// StoreDataMatrixDataCommand should return the printer-specific
+	// command to store the DataMatrix data in the symbol storage area.
+	// When the given DataMatrixConfig is in GS1 mode, the data is a GS1
+	// element string with FNC1 separators written as the GS character,
+	// and the command should start the symbol with FNC1.
+	StoreDataMatrixDataCommand(string, *DataMatrixConfig) (string, error)
+}
+
+// PrintDataMatrixData prints the DataMatrix symbol.
+type PrintDataMatrixData interface {
+	// PrintDataMatrixDataCommand should return the printer-specific
+	// command to print the DataMatrix symbol.
+	PrintDataMatrixDataCommand() (string, error)
+}
+
+// DataMatrix groups the interfaces needed to print DataMatrix symbols.
+type DataMatrix interface {
+	SelectDataMatrixType
+	SetDataMatrixModuleSize
+	StoreDataMatrixData
+	PrintDataMatrixData
+}
+
+// SelectAztecMode selects the Aztec symbol type and number of layers.
+type SelectAztecMode interface {
+	// SelectAztecModeCommand should return the printer-specific command
+	// to set the Aztec symbol type and layers based on the given
+	// AztecConfig.
+	SelectAztecModeCommand(*AztecConfig) (string, error)
+}
+
+// SetAztecModuleSize sets the Aztec module size.
+type SetAztecModuleSize interface {
+	// SetAztecModuleSizeCommand should return the printer-specific
+	// command to set the Aztec module size based on the given
+	// AztecConfig.
+	SetAztecModuleSizeCommand(*AztecConfig) (string, error)
+}
+
+// SetAztecErrorCorrection sets the Aztec error correction level.
+type SetAztecErrorCorrection interface {
+	// SetAztecErrorCorrectionCommand should return the printer-specific
+	// command to set the Aztec error correction level based on the given
+	// AztecConfig.
+	SetAztecErrorCorrectionCommand(*AztecConfig) (string, error)
+}
+
+// StoreAztecData stores the Aztec data in the symbol storage area.
+type StoreAztecData interface {
+	// StoreAztecDataCommand should return the printer-specific command
+	// to store the Aztec data in the symbol storage area.
+	StoreAztecDataCommand(string) (string, error)
+}
+
+// PrintAztecData prints the Aztec symbol.
+type PrintAztecData interface {
+	// PrintAztecDataCommand should return the printer-specific command
+	// to print the Aztec symbol.
+	PrintAztecDataCommand() (string, error)
+}
+
+// Aztec groups the interfaces needed to print Aztec symbols.
+type Aztec interface {
+	SelectAztecMode
+	SetAztecModuleSize
+	SetAztecErrorCorrection
+	StoreAztecData
+	PrintAztecData
+}
+
+// QrCodeStructuredAppend allows a QR code to be printed as one of a
+// series of linked symbols.
+type QrCodeStructuredAppend interface {
+	// QrCodeStructuredAppendCommand should return the printer-specific
+	// command to mark the next stored QR code as symbol index (from 0) of
+	// total, with the parity byte of the whole data.
+	QrCodeStructuredAppendCommand(index int, total int, parity byte) (string, error)
+}
+
+// PageMode allows for laying out content at positions in a page area
+// before printing it in one pass.
+type PageMode interface {
+	// EnterPageModeCommand should return the printer-specific command to
+	// switch from standard mode to page mode.
+	EnterPageModeCommand() (string, error)
+
+	// SetPageAreaCommand should return the printer-specific command to
+	// set the page area, in dots from the top left of the page.
+	SetPageAreaCommand(x int, y int, width int, height int) (string, error)
+
+	// SetPagePositionCommand should return the printer-specific command
+	// to move the print position to x, y dots in the page area. Symbols
+	// are printed with their bottom left corner at the print position.
+	SetPagePositionCommand(x int, y int) (string, error)
+
+	// PrintPageCommand should return the printer-specific command to
+	// print the page and return to standard mode.
+	PrintPageCommand() (string, error)
+}
+
+// CodeTables allows for printing text in the character code tables
+// selected with ESC t, which the Client transcodes UTF-8 text into.
+type CodeTables interface {
+	// CodeTables should return the names of the character code tables
+	// the printer supports, most preferred first. Names include CP437,
+	// CP850, CP852, CP858, CP860, CP863, CP865, CP866, Katakana,
+	// ISO8859-2, ISO8859-15 and WPC1250 to WPC1258.
+	CodeTables() []string
+
+	// SelectCodeTableCommand should return the printer-specific command
+	// to select the named character code table.
+	SelectCodeTableCommand(table string) (string, error)
+}
+
+// MultiByteEncodings allows for printing text in a multi-byte character
+// encoding, such as Shift-JIS or GB18030, in the Kanji or Chinese
+// character mode of printers with Japanese, Chinese or Korean firmware.
+type MultiByteEncodings interface {
+	// MultiByteEncodings should return the names of the multi-byte
+	// encodings the printer's firmware supports, most preferred first.
+	// Names include Shift-JIS, GB18030, Big5 and KS C 5601.
+	MultiByteEncodings() []string
+
+	// EnableMultiByteCommand should return the printer-specific command
+	// to select the named encoding and enter multi-byte character mode.
+	EnableMultiByteCommand(encoding string) (string, error)
+
+	// DisableMultiByteCommand should return the printer-specific command
+	// to leave the multi-byte character mode of the named encoding, so
+	// text is printed in the selected code table again.
+	DisableMultiByteCommand(encoding string) (string, error)
+}
+
+// UTF8 allows for printing UTF-8 text directly, on printers whose
+// firmware decodes it, rather than transcoding it into code tables.
+type UTF8 interface {
+	// SelectUTF8Command should return the printer-specific command to
+	// select the UTF-8 encoding, giving the named font priority for
+	// characters which several fonts have glyphs for, such as Han
+	// characters. Font names include ANK, Japanese, Simplified Chinese,
+	// Traditional Chinese and Korean, and an empty name keeps the
+	// printer's font priority. Profiles for printers whose firmware does
+	// not decode UTF-8 should return an UnsupportedError, and the Client
+	// will transcode text instead.
+	SelectUTF8Command(priority string) (string, error)
+}
+
+// InternationalCharacterSet allows for selecting the international
+// character set with ESC R, which replaces ASCII characters such as #,
+// $ and @ with national characters such as £, ¥ and §.
+type InternationalCharacterSet interface {
+	// SelectInternationalCharacterSetCommand should return the
+	// printer-specific command to select the named international
+	// character set. Names include USA, France, Germany, UK, Denmark I,
+	// Sweden, Italy, Spain I, Japan, Norway, Denmark II, Spain II,
+	// Latin America, Korea, Slovenia/Croatia, China and Vietnam. The
+	// India sets are not included, as they select Indian script fonts
+	// on India models rather than replacing ASCII characters.
+	SelectInternationalCharacterSetCommand(set string) (string, error)
+}
+
+// RealTimeStatus allows for querying the status of the printer, such as
+// whether it is out of paper, on clients which can read its replies.
+type RealTimeStatus interface {
+	// StatusCommand should return the printer-specific command to make
+	// the printer reply with the named status: printer, offline, error
+	// or paper.
+	StatusCommand(kind string) (string, error)
+
+	// DecodeStatus should set the fields of the Status which the reply
+	// to the named status reports on, returning a StatusError if the
+	// reply is not a status.
+	DecodeStatus(kind string, reply byte, status *Status) error
+}
+
+// AutomaticStatusBack allows for the printer to send its status
+// whenever it changes, on clients which can read from it.
+type AutomaticStatusBack interface {
+	// EnableAutomaticStatusBackCommand should return the printer-specific
+	// command making the printer send its status whenever it changes.
+	EnableAutomaticStatusBackCommand() (string, error)
+
+	// DisableAutomaticStatusBackCommand should return the printer-specific
+	// command stopping the printer sending its status.
+	DisableAutomaticStatusBackCommand() (string, error)
+
+	// AutomaticStatusBackLength should return the length in bytes of the
+	// status message starting with the given byte, or 0 if the byte does
+	// not start a status message, so that status messages can be told
+	// apart from replies to queries.
+	AutomaticStatusBackLength(first byte) int
+
+	// DecodeAutomaticStatusBack should set the fields of the Status from
+	// a status message, returning a StatusError if the message is not a
+	// status.
+	DecodeAutomaticStatusBack(message []byte, status *Status) error
+}
+
+// ProcessID allows for confirming that the printer has processed, and
+// so printed, everything sent to it, on clients which can read from it.
+type ProcessID interface {
+	// ProcessIDCommand should return the printer-specific command making
+	// the printer reply with the given four-digit process ID once it has
+	// processed the data sent before the command.
+	ProcessIDCommand(id string) (string, error)
+
+	// ProcessIDResponseLength should return the length in bytes of the
+	// process ID response starting with the given byte, or 0 if the byte
+	// does not start a process ID response.
+	ProcessIDResponseLength(first byte) int
+
+	// DecodeProcessIDResponse should return the process ID a response
+	// carries, returning a StatusError if the response is not a process
+	// ID response.
+	DecodeProcessIDResponse(response []byte) (string, error)
+}
+
+// Identification allows for the printer to report its model and
+// firmware, on clients which can read from it.
+type Identification interface {
+	// IdentificationCommand should return the printer-specific command to
+	// make the printer reply with the named information: model ID, type
+	// ID, firmware version, manufacturer, model name or serial number.
+	IdentificationCommand(kind string) (string, error)
+
+	// IdentificationBlock should report whether the given byte starts a
+	// reply which runs until a NUL byte, so that the reply is read whole
+	// rather than mistaken for other messages from the printer.
+	IdentificationBlock(first byte) bool
+
+	// DecodeIdentification should set the field of the PrinterInfo which
+	// the reply to the named information holds, returning a StatusError
+	// if the reply is not valid.
+	DecodeIdentification(kind string, reply []byte, info *PrinterInfo) error
+}
+
+// CashDrawer allows for opening a cash drawer wired to the printer's
+// drawer kick-out connector.
+type CashDrawer interface {
+	// DrawerPulseCommand should return the printer-specific command to
+	// send a pulse to the given pin of the drawer kick-out connector, 2
+	// or 5, switched on for onTime and then off for offTime. The pulse is
+	// sent once the printer has processed the data sent before it.
+	DrawerPulseCommand(pin int, onTime time.Duration, offTime time.Duration) (string, error)
+
+	// RealTimeDrawerPulseCommand should return the printer-specific
+	// command to send a pulse to the given pin straight away, even when
+	// the printer is offline or its buffer is full, switched on and then
+	// off for the pulse time each.
+	RealTimeDrawerPulseCommand(pin int, pulse time.Duration) (string, error)
+}
+
+// Feed allows for feeding the paper without printing.
+type Feed interface {
+	// FeedLinesCommand should return the printer-specific command to print
+	// the buffered data and feed the paper by the given number of lines.
+	FeedLinesCommand(lines int) (string, error)
+
+	// FeedDotsCommand should return the printer-specific command to print
+	// the buffered data and feed the paper by the given number of dots,
+	// converting dots into the printer's vertical motion units.
+	FeedDotsCommand(dots int) (string, error)
+}
+
+// CutterPosition describes where the cutter is. For printers which
+// cannot feed the paper to the cutter themselves, the Client feeds the
+// paper by the distance before a cut which feeds, and asks the profile
+// for a cut without feed.
+type CutterPosition interface {
+	// CutterDistance should return the distance in dots between the
+	// print head and the cutter.
+	CutterDistance() int
+	// FeedsToCutter should report whether the profile's cut command
+	// feeds the paper to the cutter itself when asked to, so the Client
+	// does not feed it first.
+	FeedsToCutter() bool
+}
+
+// ReverseFeed allows for feeding the paper backwards, on printers which
+// can.
+type ReverseFeed interface {
+	// ReverseFeedLinesCommand should return the printer-specific command
+	// to print the buffered data and feed the paper back by the given
+	// number of lines.
+	ReverseFeedLinesCommand(lines int) (string, error)
+}
+
+// Spacing allows for the spacing between lines and characters to be
+// changed.
+type Spacing interface {
+	// LineSpacingCommand should return the printer-specific command to
+	// set the line spacing to the given number of dots, converting dots
+	// into the printer's vertical motion units.
+	LineSpacingCommand(dots int) (string, error)
+
+	// DefaultLineSpacingCommand should return the printer-specific
+	// command to restore the default line spacing.
+	DefaultLineSpacingCommand() (string, error)
+
+	// CharSpacingCommand should return the printer-specific command to
+	// set the space to the right of each character to the given number
+	// of dots, converting dots into the printer's horizontal motion
+	// units.
+	CharSpacingCommand(dots int) (string, error)
+}
+
+// Positioning allows for setting the margins of the print area and the
+// horizontal print position.
+type Positioning interface {
+	// LeftMarginCommand should return the printer-specific command to set
+	// the left margin to the given number of dots, converting dots into
+	// the printer's horizontal motion units.
+	LeftMarginCommand(dots int) (string, error)
+
+	// PrintAreaWidthCommand should return the printer-specific command to
+	// set the width of the print area to the given number of dots.
+	PrintAreaWidthCommand(dots int) (string, error)
+
+	// AbsolutePositionCommand should return the printer-specific command
+	// to move the print position to the given number of dots from the
+	// left margin.
+	AbsolutePositionCommand(dots int) (string, error)
+
+	// RelativePositionCommand should return the printer-specific command
+	// to move the print position by the given number of dots, to the left
+	// when negative.
+	RelativePositionCommand(dots int) (string, error)
+}
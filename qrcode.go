@@ -1,20 +1,26 @@
 package escpos
 
+import "strings"
+
 type QrCodeConfig struct {
-	model           string
-	size            uint
-	errorCorrection string
-	justification   string
+	model            string
+	size             uint
+	errorCorrection  string
+	justification    string
+	structuredAppend bool
+	sideBySide       bool
 }
 
 // DefaultQrCodeConfig creates a QrCodeConfig containing sensible
 // default values for QR code printing.
 func DefaultQrCodeConfig() QrCodeConfig {
 	return QrCodeConfig{
-		model:           "2",
-		size:            3,
-		errorCorrection: "L",
-		justification:   "center",
+		model:            "2",
+		size:             3,
+		errorCorrection:  "L",
+		justification:    "center",
+		structuredAppend: false,
+		sideBySide:       false,
 	}
 }
 
@@ -42,3 +48,220 @@ func (cfg QrCodeConfig) ErrorCorrection(level string) QrCodeConfig {
 	cfg.errorCorrection = level
 	return cfg
 }
+
+// StructuredAppend sets whether data too long for a single QR code, at
+// the size and error correction level of the config and within the
+// printable width, is split across up to 16 linked symbols which a
+// scanner reassembles. The default is false.
+func (cfg QrCodeConfig) StructuredAppend(enabled bool) QrCodeConfig {
+	cfg.structuredAppend = enabled
+	return cfg
+}
+
+// SideBySide sets whether structured append symbols are laid out next
+// to each other using page mode, wrapping onto further rows as needed,
+// rather than printed one after another. The default is false.
+func (cfg QrCodeConfig) SideBySide(enabled bool) QrCodeConfig {
+	cfg.sideBySide = enabled
+	return cfg
+}
+
+// qrEccCodewordsPerBlock and qrEccBlocks hold, for each error
+// correction level, the number of error correction codewords in each
+// block and the number of blocks of each model 2 QR code version. Index
+// 0 is unused.
+var qrEccCodewordsPerBlock = map[string][41]int{
+	"L": {0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	"M": {0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	"Q": {0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	"H": {0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var qrEccBlocks = map[string][41]int{
+	"L": {0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	"M": {0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	"Q": {0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	"H": {0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// qrMaxVersions holds the largest version of each QR code model.
+var qrMaxVersions = map[string]int{"1": 14, "2": 40}
+
+// qrModel1Codewords holds the total number of codewords of each model 1
+// QR code version. Index 0 is unused.
+var qrModel1Codewords = [15]int{0, 26, 46, 72, 100, 134, 170, 212, 256, 306, 358, 416, 476, 542, 610}
+
+// qrModel1EccPercent holds, for each error correction level, the
+// percentage of the codewords of a model 1 QR code taken up by error
+// correction. Each is above the share any model 2 version uses at the
+// level, so model 1 capacities are underestimated rather than over.
+var qrModel1EccPercent = map[string]int{"L": 28, "M": 42, "Q": 56, "H": 70}
+
+// qrAlphanumericCharset holds the characters of the QR code
+// alphanumeric mode, in order of their values.
+const qrAlphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// qrRawDataModules returns the number of modules of a model 2 QR code
+// version which are available for data and error correction codewords,
+// after function patterns and format and version information.
+func qrRawDataModules(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		alignments := version/7 + 2
+		modules -= (25*alignments-10)*alignments - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules
+}
+
+// qrDataCodewords returns the number of data codewords of a model 2 QR
+// code version at the error correction level.
+func qrDataCodewords(version int, level string) int {
+	return qrRawDataModules(version)/8 - qrEccCodewordsPerBlock[level][version]*qrEccBlocks[level][version]
+}
+
+// qrModel1DataCodewords returns a lower bound on the number of data
+// codewords of a model 1 QR code version at the error correction level.
+func qrModel1DataCodewords(version int, level string) int {
+	codewords := qrModel1Codewords[version]
+	return codewords - (codewords*qrModel1EccPercent[level]+99)/100
+}
+
+// qrMode returns the most compact QR code mode that can encode all of
+// the data: numeric, alphanumeric or byte.
+func qrMode(data []byte) string {
+	mode := "numeric"
+	for _, b := range data {
+		switch {
+		case b >= '0' && b <= '9':
+		case strings.IndexByte(qrAlphanumericCharset, b) >= 0:
+			mode = "alphanumeric"
+		default:
+			return "byte"
+		}
+	}
+	return mode
+}
+
+// qrSegmentBits returns the number of bits needed to encode the data
+// as a single segment in the mode at the version, including the mode
+// indicator and character count.
+func qrSegmentBits(data []byte, mode string, version int) int {
+	sizeClass := 0
+	if version >= 27 {
+		sizeClass = 2
+	} else if version >= 10 {
+		sizeClass = 1
+	}
+
+	n := len(data)
+	switch mode {
+	case "numeric":
+		return 4 + []int{10, 12, 14}[sizeClass] + 10*(n/3) + []int{0, 4, 7}[n%3]
+	case "alphanumeric":
+		return 4 + []int{9, 11, 13}[sizeClass] + 11*(n/2) + 6*(n%2)
+	default:
+		return 4 + []int{8, 16, 16}[sizeClass] + 8*n
+	}
+}
+
+// QrCodeInfo describes the QR code symbol needed to encode some data.
+type QrCodeInfo struct {
+	// Version is the QR code version, from 1 to 40 for model 2 and 1 to
+	// 14 for model 1.
+	Version int
+	// Modules is the number of modules along each side of the symbol.
+	Modules int
+	// Width is the width in dots of the printed symbol, excluding the
+	// quiet zone.
+	Width int
+}
+
+// PlanQrCode works out the QR code symbol that the data needs with the
+// model, size and error correction level of the given QrCodeConfig,
+// returning an error if the data does not fit in the largest version.
+// The data is assumed to be encoded in a single mode, which is what
+// printers do for data that is all digits, all alphanumeric or binary.
+// Model 1 capacities are bounded conservatively, so data close to the
+// limit of a model 1 version is planned for the next version or
+// rejected although the printer might fit it.
+func PlanQrCode(data []byte, cfg QrCodeConfig) (QrCodeInfo, error) {
+	return planQrCode(data, cfg, 0)
+}
+
+// qrStructuredAppendBits is the size of the structured append header
+// which precedes the data of each linked symbol.
+const qrStructuredAppendBits = 20
+
+// planQrCode works out the QR code symbol that the data needs after a
+// header of the given number of bits.
+func planQrCode(data []byte, cfg QrCodeConfig, headerBits int) (QrCodeInfo, error) {
+	maxVersion, ok := qrMaxVersions[cfg.model]
+	if !ok {
+		return QrCodeInfo{}, invalidConfig("invalid model in QrCodeConfig: %v", cfg.model)
+	}
+	if _, ok := qrEccBlocks[cfg.errorCorrection]; !ok {
+		return QrCodeInfo{}, invalidConfig("invalid error correction level option in QrCodeConfig: %v", cfg.errorCorrection)
+	}
+	if len(data) == 0 {
+		return QrCodeInfo{}, invalidConfig("QR code data is empty")
+	}
+
+	mode := qrMode(data)
+	for version := 1; version <= maxVersion; version++ {
+		capacity := qrDataCodewords(version, cfg.errorCorrection)
+		if cfg.model == "1" {
+			capacity = qrModel1DataCodewords(version, cfg.errorCorrection)
+		}
+		if headerBits+qrSegmentBits(data, mode, version) <= capacity*8 {
+			modules := 17 + 4*version
+			return QrCodeInfo{Version: version, Modules: modules, Width: modules * int(cfg.size)}, nil
+		}
+	}
+
+	return QrCodeInfo{}, invalidConfig("QR code data too long for model %v at error correction level %v: %v bytes in %v mode", cfg.model, cfg.errorCorrection, len(data), mode)
+}
+
+// splitQrCode splits the data into the fewest parts, up to 16, which
+// each fit a structured append symbol no wider than maxWidth dots. The
+// plan of the largest symbol is returned with the parts.
+func splitQrCode(data []byte, cfg QrCodeConfig, maxWidth int) ([][]byte, QrCodeInfo, error) {
+	if _, err := planQrCode(data[:min(len(data), 1)], cfg, qrStructuredAppendBits); err != nil {
+		return nil, QrCodeInfo{}, err
+	}
+
+	for total := 2; total <= 16 && total <= len(data); total++ {
+		partLength := (len(data) + total - 1) / total
+
+		var parts [][]byte
+		var largest QrCodeInfo
+		fits := true
+		for start := 0; start < len(data) && fits; start += partLength {
+			part := data[start:min(start+partLength, len(data))]
+			info, err := planQrCode(part, cfg, qrStructuredAppendBits)
+			fits = err == nil && info.Width <= maxWidth
+			if info.Version > largest.Version {
+				largest = info
+			}
+			parts = append(parts, part)
+		}
+
+		if fits {
+			return parts, largest, nil
+		}
+	}
+
+	return nil, QrCodeInfo{}, invalidConfig("QR code data too long for 16 structured append symbols no wider than %v dots: %v bytes", maxWidth, len(data))
+}
+
+// qrParity returns the structured append parity of the data, which is
+// all of its bytes combined with exclusive or.
+func qrParity(data []byte) byte {
+	var parity byte
+	for _, b := range data {
+		parity ^= b
+	}
+	return parity
+}
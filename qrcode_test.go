@@ -0,0 +1,130 @@
+package escpos
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPlanQrCode(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []byte
+		cfg         QrCodeConfig
+		wantVersion int
+	}{
+		{"17 bytes fit version 1 at level L", bytes.Repeat([]byte("a"), 17), DefaultQrCodeConfig(), 1},
+		{"18 bytes need version 2 at level L", bytes.Repeat([]byte("a"), 18), DefaultQrCodeConfig(), 2},
+		{"213 bytes fit version 10 at level M", bytes.Repeat([]byte("a"), 213), DefaultQrCodeConfig().ErrorCorrection("M"), 10},
+		{"715 bytes fit version 25 at level Q", bytes.Repeat([]byte("a"), 715), DefaultQrCodeConfig().ErrorCorrection("Q"), 25},
+		{"1273 bytes fit version 40 at level H", bytes.Repeat([]byte("a"), 1273), DefaultQrCodeConfig().ErrorCorrection("H"), 40},
+		{"2953 bytes fit version 40 at level L", bytes.Repeat([]byte("a"), 2953), DefaultQrCodeConfig(), 40},
+		{"7089 digits fit version 40 at level L", bytes.Repeat([]byte("1"), 7089), DefaultQrCodeConfig(), 40},
+		{"4296 alphanumerics fit version 40 at level L", bytes.Repeat([]byte("A"), 4296), DefaultQrCodeConfig(), 40},
+		{"41 digits fit version 1 at level L", bytes.Repeat([]byte("1"), 41), DefaultQrCodeConfig(), 1},
+		{"binary data is accepted", []byte{0x00, 0xFF, 0xC3, 0x28}, DefaultQrCodeConfig(), 1},
+		{"16 bytes fit model 1 version 1 at level L", bytes.Repeat([]byte("a"), 16), DefaultQrCodeConfig().Model("1"), 1},
+		{"17 bytes need model 1 version 2 at level L", bytes.Repeat([]byte("a"), 17), DefaultQrCodeConfig().Model("1"), 2},
+		{"436 bytes fit model 1 version 14 at level L", bytes.Repeat([]byte("a"), 436), DefaultQrCodeConfig().Model("1"), 14},
+		{"180 bytes fit model 1 version 14 at level H", bytes.Repeat([]byte("a"), 180), DefaultQrCodeConfig().Model("1").ErrorCorrection("H"), 14},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := PlanQrCode(testCase.data, testCase.cfg)
+
+			if err != nil {
+				t.Fatalf("PlanQrCode returned error: %v", err)
+			}
+
+			modules := 17 + 4*testCase.wantVersion
+
+			if got.Version != testCase.wantVersion || got.Modules != modules || got.Width != modules*3 {
+				t.Errorf("PlanQrCode did not return expected info: wanted version %v, got %+v", testCase.wantVersion, got)
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name string
+		data []byte
+		cfg  QrCodeConfig
+	}{
+		{"2954 bytes do not fit at level L", bytes.Repeat([]byte("a"), 2954), DefaultQrCodeConfig()},
+		{"437 bytes do not fit model 1 at level L", bytes.Repeat([]byte("a"), 437), DefaultQrCodeConfig().Model("1")},
+		{"181 bytes do not fit model 1 at level H", bytes.Repeat([]byte("a"), 181), DefaultQrCodeConfig().Model("1").ErrorCorrection("H")},
+		{"empty data returns error", []byte{}, DefaultQrCodeConfig()},
+		{"unknown model returns error", []byte("a"), DefaultQrCodeConfig().Model("micro")},
+		{"unknown error correction returns error", []byte("a"), DefaultQrCodeConfig().ErrorCorrection("X")},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := PlanQrCode(testCase.data, testCase.cfg)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("PlanQrCode did not return config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSplitQrCode(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []byte
+		maxWidth    int
+		wantParts   int
+		wantVersion int
+	}{
+		{"data too long for one symbol is split in two", bytes.Repeat([]byte("a"), 3000), 576, 2, 28},
+		{"narrow print area needs more symbols", bytes.Repeat([]byte("a"), 1000), 200, 3, 12},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parts, info, err := splitQrCode(testCase.data, DefaultQrCodeConfig(), testCase.maxWidth)
+
+			if err != nil {
+				t.Fatalf("splitQrCode returned error: %v", err)
+			}
+
+			if len(parts) != testCase.wantParts || info.Version != testCase.wantVersion {
+				t.Errorf("splitQrCode did not return expected split: wanted %v parts at version %v, got %v parts at version %v", testCase.wantParts, testCase.wantVersion, len(parts), info.Version)
+			}
+
+			if joined := bytes.Join(parts, nil); !bytes.Equal(joined, testCase.data) {
+				t.Errorf("splitQrCode parts do not join to the data: got %v bytes", len(joined))
+			}
+		})
+	}
+
+	negativeCases := []struct {
+		name     string
+		data     []byte
+		cfg      QrCodeConfig
+		maxWidth int
+	}{
+		{"data too long for 16 symbols returns error", bytes.Repeat([]byte("a"), 3000), DefaultQrCodeConfig(), 100},
+		{"unknown model returns error", []byte("abc"), DefaultQrCodeConfig().Model("micro"), 576},
+		{"empty data returns error", []byte{}, DefaultQrCodeConfig(), 576},
+	}
+
+	for _, testCase := range negativeCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, _, err := splitQrCode(testCase.data, testCase.cfg, testCase.maxWidth)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("splitQrCode did not return config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestQrParity(t *testing.T) {
+	got := qrParity([]byte{0x01, 0x02, 0x04, 0x80})
+
+	if got != 0x87 {
+		t.Errorf("qrParity did not return expected parity: wanted %#x, got %#x", 0x87, got)
+	}
+}
@@ -0,0 +1,491 @@
+package escpos
+
+import "strings"
+
+// qrStructuredAppend is the structured append header of a symbol which
+// is one of a series of linked QR codes.
+type qrStructuredAppend struct {
+	index  int
+	total  int
+	parity byte
+}
+
+// qrBits is a bit stream, most significant bit first.
+type qrBits []byte
+
+func (bits *qrBits) append(value int, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*bits = append(*bits, byte(value>>i&1))
+	}
+}
+
+// qrFormatLevels holds the bits identifying each error correction
+// level in the format information.
+var qrFormatLevels = map[string]int{"L": 1, "M": 0, "Q": 3, "H": 2}
+
+// qrSymbol is the matrix of modules of a QR code, with dark modules
+// set, and which of them belong to function patterns.
+type qrSymbol struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// renderQrCode encodes the data as a model 2 QR code with the error
+// correction level of the config, preceded by the structured append
+// header if one is given, and draws it with each module a square of
+// the config size in dots. Quiet zones are left to the surrounding
+// paper, as with printers which render QR codes natively.
+func renderQrCode(data []byte, cfg *QrCodeConfig, header *qrStructuredAppend) (*Bitmap, error) {
+	symbol, err := encodeQrCode(data, cfg, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.size < 1 || cfg.size > 16 {
+		return nil, invalidConfig("invalid size option in QrCodeConfig: %v", cfg.size)
+	}
+	scale := int(cfg.size)
+
+	bitmap := NewBitmap(symbol.size*scale, symbol.size*scale)
+	for y := 0; y < bitmap.Height; y++ {
+		for x := 0; x < bitmap.Width; x++ {
+			bitmap.SetBlack(x, y, symbol.modules[y/scale][x/scale])
+		}
+	}
+
+	return bitmap, nil
+}
+
+// encodeQrCode encodes the data into the modules of the smallest model
+// 2 QR code which holds it.
+func encodeQrCode(data []byte, cfg *QrCodeConfig, header *qrStructuredAppend) (*qrSymbol, error) {
+	headerBits := 0
+	if header != nil {
+		headerBits = qrStructuredAppendBits
+	}
+
+	info, err := planQrCode(data, *cfg, headerBits)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.model != "2" {
+		return nil, invalidConfig("invalid model for rendered QR code, expected 2: %v", cfg.model)
+	}
+
+	codewords := qrAddErrorCorrection(qrDataCodewordsFor(data, info.Version, cfg.errorCorrection, header), info.Version, cfg.errorCorrection)
+
+	symbol := newQrSymbol(info.Version)
+	symbol.drawCodewords(codewords)
+
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		symbol.applyMask(mask)
+		symbol.drawFormat(cfg.errorCorrection, mask)
+		if penalty := symbol.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		symbol.applyMask(mask)
+	}
+	symbol.applyMask(bestMask)
+	symbol.drawFormat(cfg.errorCorrection, bestMask)
+
+	return symbol, nil
+}
+
+// qrDataCodewordsFor builds the data codewords of the version: the
+// header, the data as a single segment, a terminator and padding.
+func qrDataCodewordsFor(data []byte, version int, level string, header *qrStructuredAppend) []byte {
+	var bits qrBits
+	if header != nil {
+		bits.append(3, 4)
+		bits.append(header.index, 4)
+		bits.append(header.total-1, 4)
+		bits.append(int(header.parity), 8)
+	}
+
+	sizeClass := 0
+	if version >= 27 {
+		sizeClass = 2
+	} else if version >= 10 {
+		sizeClass = 1
+	}
+
+	switch mode := qrMode(data); mode {
+	case "numeric":
+		bits.append(1, 4)
+		bits.append(len(data), []int{10, 12, 14}[sizeClass])
+		for i := 0; i < len(data); i += 3 {
+			group := data[i:min(i+3, len(data))]
+			value := 0
+			for _, digit := range group {
+				value = value*10 + int(digit-'0')
+			}
+			bits.append(value, []int{0, 4, 7, 10}[len(group)])
+		}
+	case "alphanumeric":
+		bits.append(2, 4)
+		bits.append(len(data), []int{9, 11, 13}[sizeClass])
+		for i := 0; i+1 < len(data); i += 2 {
+			bits.append(strings.IndexByte(qrAlphanumericCharset, data[i])*45+strings.IndexByte(qrAlphanumericCharset, data[i+1]), 11)
+		}
+		if len(data)%2 == 1 {
+			bits.append(strings.IndexByte(qrAlphanumericCharset, data[len(data)-1]), 6)
+		}
+	default:
+		bits.append(4, 4)
+		bits.append(len(data), []int{8, 16, 16}[sizeClass])
+		for _, b := range data {
+			bits.append(int(b), 8)
+		}
+	}
+
+	capacity := qrDataCodewords(version, level) * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := make([]byte, 0, capacity/8)
+	for i := 0; i < len(bits); i += 8 {
+		var codeword byte
+		for _, bit := range bits[i : i+8] {
+			codeword = codeword<<1 | bit
+		}
+		codewords = append(codewords, codeword)
+	}
+	for pad := byte(0xEC); len(codewords) < capacity/8; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+
+	return codewords
+}
+
+// qrAddErrorCorrection splits the data codewords into blocks, adds
+// Reed-Solomon error correction codewords to each and interleaves them
+// into the final codeword sequence.
+func qrAddErrorCorrection(data []byte, version int, level string) []byte {
+	blocks := qrEccBlocks[level][version]
+	eccLength := qrEccCodewordsPerBlock[level][version]
+	rawCodewords := qrRawDataModules(version) / 8
+	shortBlocks := blocks - rawCodewords%blocks
+	shortLength := rawCodewords/blocks - eccLength
+
+	divisor := qrReedSolomonDivisor(eccLength)
+	dataBlocks := make([][]byte, blocks)
+	eccBlocks := make([][]byte, blocks)
+	for i, start := 0, 0; i < blocks; i++ {
+		length := shortLength
+		if i >= shortBlocks {
+			length++
+		}
+		dataBlocks[i] = data[start : start+length]
+		eccBlocks[i] = qrReedSolomonRemainder(dataBlocks[i], divisor)
+		start += length
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i <= shortLength; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < eccLength; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+// qrMultiply multiplies two elements of the Galois field GF(256) used
+// by QR codes, with the reducing polynomial x^8 + x^4 + x^3 + x^2 + 1.
+func qrMultiply(x byte, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// qrReedSolomonDivisor returns the coefficients, highest power first
+// and excluding the leading 1, of the generator polynomial of the
+// degree.
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 2)
+	}
+
+	return result
+}
+
+// qrReedSolomonRemainder returns the error correction codewords of the
+// data, which are the remainder of dividing it by the divisor.
+func qrReedSolomonRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= qrMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// qrAlignmentPositions returns the row and column coordinates of the
+// centres of the alignment patterns of the version.
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	count := version/7 + 2
+	step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, position := count-1, 17+4*version-7; i >= 1; i, position = i-1, position-step {
+		positions[i] = position
+	}
+	return positions
+}
+
+// newQrSymbol creates the symbol of the version with its function
+// patterns drawn and the format and version information reserved.
+func newQrSymbol(version int) *qrSymbol {
+	size := 17 + 4*version
+	symbol := &qrSymbol{size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range symbol.modules {
+		symbol.modules[y] = make([]bool, size)
+		symbol.function[y] = make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ {
+		symbol.setFunction(6, i, i%2 == 0)
+		symbol.setFunction(i, 6, i%2 == 0)
+	}
+
+	for _, centre := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := centre[0]+dx, centre[1]+dy
+				if x >= 0 && x < size && y >= 0 && y < size {
+					distance := max(abs(dx), abs(dy))
+					symbol.setFunction(x, y, distance != 2 && distance != 4)
+				}
+			}
+		}
+	}
+
+	positions := qrAlignmentPositions(version)
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					symbol.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	symbol.drawFormat("L", 0)
+
+	if version >= 7 {
+		remainder := version
+		for i := 0; i < 12; i++ {
+			remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+		}
+		bits := version<<12 | remainder
+		for i := 0; i < 18; i++ {
+			a, b := size-11+i%3, i/3
+			symbol.setFunction(a, b, bits>>i&1 != 0)
+			symbol.setFunction(b, a, bits>>i&1 != 0)
+		}
+	}
+
+	return symbol
+}
+
+func (symbol *qrSymbol) setFunction(x int, y int, dark bool) {
+	symbol.modules[y][x] = dark
+	symbol.function[y][x] = true
+}
+
+// drawFormat draws both copies of the format information for the error
+// correction level and mask, and the dark module.
+func (symbol *qrSymbol) drawFormat(level string, mask int) {
+	data := qrFormatLevels[level]<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	size := symbol.size
+	for i := 0; i <= 5; i++ {
+		symbol.setFunction(8, i, bit(i))
+	}
+	symbol.setFunction(8, 7, bit(6))
+	symbol.setFunction(8, 8, bit(7))
+	symbol.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		symbol.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		symbol.setFunction(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		symbol.setFunction(8, size-15+i, bit(i))
+	}
+	symbol.setFunction(8, size-8, true)
+}
+
+// drawCodewords places the codewords in the modules which are not part
+// of function patterns, in two module wide columns zigzagging up and
+// down from the bottom right corner.
+func (symbol *qrSymbol) drawCodewords(codewords []byte) {
+	i := 0
+	for right := symbol.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < symbol.size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = symbol.size - 1 - vertical
+				}
+				if !symbol.function[y][x] && i < len(codewords)*8 {
+					symbol.modules[y][x] = codewords[i/8]>>(7-i%8)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the modules outside function patterns selected by
+// the mask pattern. Applying the same mask twice undoes it.
+func (symbol *qrSymbol) applyMask(mask int) {
+	for y := 0; y < symbol.size; y++ {
+		for x := 0; x < symbol.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !symbol.function[y][x] {
+				symbol.modules[y][x] = !symbol.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol by the rules used to choose a mask: runs
+// of the same colour, 2x2 blocks, patterns resembling finders and an
+// imbalance of dark and light modules.
+func (symbol *qrSymbol) penalty() int {
+	size := symbol.size
+	penalty := 0
+	dark := 0
+
+	for _, transposed := range []bool{false, true} {
+		at := func(a int, b int) bool {
+			if a < 0 || a >= size {
+				return false
+			}
+			if transposed {
+				return symbol.modules[a][b]
+			}
+			return symbol.modules[b][a]
+		}
+
+		for line := 0; line < size; line++ {
+			run := 1
+			for i := 1; i <= size; i++ {
+				if i < size && at(i, line) == at(i-1, line) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+
+			for i := 0; i+7 <= size; i++ {
+				if !at(i, line) || at(i+1, line) || !at(i+2, line) || !at(i+3, line) || !at(i+4, line) || at(i+5, line) || !at(i+6, line) {
+					continue
+				}
+				lightBefore, lightAfter := true, true
+				for j := 1; j <= 4; j++ {
+					lightBefore = lightBefore && !at(i-j, line)
+					lightAfter = lightAfter && !at(i+6+j, line)
+				}
+				if lightBefore || lightAfter {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if symbol.modules[y][x] {
+				dark++
+			}
+			if x+1 < size && y+1 < size {
+				colour := symbol.modules[y][x]
+				if symbol.modules[y][x+1] == colour && symbol.modules[y+1][x] == colour && symbol.modules[y+1][x+1] == colour {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	total := size * size
+	penalty += ((abs(dark*20-total*10)+total-1)/total - 1) * 10
+
+	return penalty
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
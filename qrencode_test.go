@@ -0,0 +1,92 @@
+package escpos
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestQrDataCodewordsFor(t *testing.T) {
+	got := qrDataCodewordsFor([]byte("HELLO WORLD"), 1, "M", nil)
+
+	want := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("qrDataCodewordsFor did not return expected codewords: wanted %v, got %v", want, got)
+	}
+}
+
+func TestQrAddErrorCorrection(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+
+	got := qrAddErrorCorrection(data, 1, "M")
+
+	want := append(append([]byte{}, data...), 196, 35, 39, 119, 235, 215, 231, 226, 93, 23)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("qrAddErrorCorrection did not return expected codewords: wanted %v, got %v", want, got)
+	}
+}
+
+func TestQrAlignmentPositions(t *testing.T) {
+	cases := []struct {
+		version int
+		want    []int
+	}{
+		{1, nil},
+		{2, []int{6, 18}},
+		{7, []int{6, 22, 38}},
+		{32, []int{6, 34, 60, 86, 112, 138}},
+		{40, []int{6, 30, 58, 86, 114, 142, 170}},
+	}
+
+	for _, testCase := range cases {
+		got := qrAlignmentPositions(testCase.version)
+
+		if len(got) != len(testCase.want) {
+			t.Fatalf("qrAlignmentPositions did not return expected positions for version %v: wanted %v, got %v", testCase.version, testCase.want, got)
+		}
+		for i := range got {
+			if got[i] != testCase.want[i] {
+				t.Errorf("qrAlignmentPositions did not return expected positions for version %v: wanted %v, got %v", testCase.version, testCase.want, got)
+			}
+		}
+	}
+}
+
+func TestRenderQrCode(t *testing.T) {
+	t.Run("symbol is drawn with modules scaled to the size", func(t *testing.T) {
+		cfg := DefaultQrCodeConfig().Size(2)
+
+		got, err := renderQrCode([]byte("https://github.com/reeceaw/escpos"), &cfg, nil)
+
+		if err != nil {
+			t.Fatalf("renderQrCode returned error: %v", err)
+		}
+
+		// 33 bytes need version 3 at level L, which is 29 modules across.
+		if got.Width != 58 || got.Height != 58 {
+			t.Errorf("renderQrCode did not return expected size: wanted 58x58, got %vx%v", got.Width, got.Height)
+		}
+
+		// Each finder pattern has a dark ring, a light ring and a dark
+		// 3x3 centre.
+		for _, corner := range [][2]int{{0, 0}, {22, 0}, {0, 22}} {
+			for i, want := range []bool{true, false, true, true} {
+				x, y := (corner[0]+i)*2, (corner[1]+3)*2
+				if got.Black(x, y) != want || got.Black(x+1, y+1) != want {
+					t.Errorf("renderQrCode did not draw finder pattern at module %v, %v", corner[0]+i, corner[1]+3)
+				}
+			}
+		}
+	})
+
+	t.Run("model 1 returns config error", func(t *testing.T) {
+		cfg := DefaultQrCodeConfig().Model("1")
+
+		got, err := renderQrCode([]byte("abc"), &cfg, nil)
+
+		if got != nil || err == nil {
+			t.Errorf("renderQrCode did not return error for model 1")
+		}
+	})
+}
@@ -0,0 +1,414 @@
+package escpos
+
+import (
+	"image"
+	"image/draw"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// complexScripts holds the scripts which need shaping, stacking or
+// right-to-left layout that printer fonts cannot do, so lines using
+// them are rendered as raster text whenever a raster font is set.
+var complexScripts = []*unicode.RangeTable{
+	unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko,
+	unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar, unicode.Tibetan,
+	unicode.Devanagari, unicode.Bengali, unicode.Gurmukhi, unicode.Gujarati, unicode.Oriya,
+	unicode.Tamil, unicode.Telugu, unicode.Kannada, unicode.Malayalam, unicode.Sinhala,
+}
+
+// arabicForms holds the isolated, final, initial and medial
+// presentation forms of each Arabic letter. Letters which only join to
+// the letter before them have no initial or medial form.
+var arabicForms = map[rune][4]rune{
+	'ء': {0xFE80, 0, 0, 0},
+	'آ': {0xFE81, 0xFE82, 0, 0},
+	'أ': {0xFE83, 0xFE84, 0, 0},
+	'ؤ': {0xFE85, 0xFE86, 0, 0},
+	'إ': {0xFE87, 0xFE88, 0, 0},
+	'ئ': {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	'ا': {0xFE8D, 0xFE8E, 0, 0},
+	'ب': {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	'ة': {0xFE93, 0xFE94, 0, 0},
+	'ت': {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	'ث': {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	'ج': {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	'ح': {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	'خ': {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	'د': {0xFEA9, 0xFEAA, 0, 0},
+	'ذ': {0xFEAB, 0xFEAC, 0, 0},
+	'ر': {0xFEAD, 0xFEAE, 0, 0},
+	'ز': {0xFEAF, 0xFEB0, 0, 0},
+	'س': {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	'ش': {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	'ص': {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	'ض': {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	'ط': {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	'ظ': {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	'ع': {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	'غ': {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	'ف': {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	'ق': {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	'ك': {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	'ل': {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	'م': {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	'ن': {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	'ه': {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	'و': {0xFEED, 0xFEEE, 0, 0},
+	'ى': {0xFEEF, 0xFEF0, 0xFBE8, 0xFBE9},
+	'ي': {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+	'پ': {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+	'چ': {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+	'ژ': {0xFB8A, 0xFB8B, 0, 0},
+	'ک': {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+	'گ': {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+	'ی': {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+}
+
+// lamAlefForms holds the isolated and final forms of the ligature lam
+// makes with each form of alef.
+var lamAlefForms = map[rune][2]rune{
+	'آ': {0xFEF5, 0xFEF6},
+	'أ': {0xFEF7, 0xFEF8},
+	'إ': {0xFEF9, 0xFEFA},
+	'ا': {0xFEFB, 0xFEFC},
+}
+
+// mirroredBrackets holds the character printed in place of each bracket
+// in right-to-left text.
+var mirroredBrackets = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{',
+	'<': '>', '>': '<', '«': '»', '»': '«', '‹': '›', '›': '‹',
+}
+
+// arabicJoining returns the joining type of the character: D for
+// characters joining on both sides, R for those joining only to the
+// character before them, T for marks the joining passes through and U
+// for everything else.
+func arabicJoining(r rune) byte {
+	forms, ok := arabicForms[r]
+	switch {
+	case r == 'ـ' || ok && forms[2] != 0:
+		return 'D'
+	case ok && forms[1] != 0:
+		return 'R'
+	case unicode.Is(unicode.Mn, r):
+		return 'T'
+	default:
+		return 'U'
+	}
+}
+
+// shapeArabic replaces the Arabic letters of text in logical order with
+// the presentation forms for their position in a word, and lam followed
+// by alef with their ligature.
+func shapeArabic(runes []rune) []rune {
+	// joined finds the joining type of the nearest character before or
+	// after i which is not a mark.
+	joined := func(i int, step int) byte {
+		for i += step; i >= 0 && i < len(runes); i += step {
+			if joining := arabicJoining(runes[i]); joining != 'T' {
+				return joining
+			}
+		}
+		return 'U'
+	}
+
+	shaped := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		joining := arabicJoining(r)
+		forms, ok := arabicForms[r]
+		if !ok {
+			shaped = append(shaped, r)
+			continue
+		}
+
+		joinsBefore := joining != 'U' && joined(i, -1) == 'D'
+		joinsAfter := joining == 'D' && (joined(i, 1) == 'D' || joined(i, 1) == 'R')
+
+		if ligature, ok := lamAlefForms[runeAt(runes, i+1)]; r == 'ل' && ok {
+			shaped = append(shaped, ligature[btoi(joinsBefore)])
+			i++
+			continue
+		}
+
+		switch {
+		case joinsBefore && joinsAfter:
+			shaped = append(shaped, forms[3])
+		case joinsBefore:
+			shaped = append(shaped, forms[1])
+		case joinsAfter:
+			shaped = append(shaped, forms[2])
+		default:
+			shaped = append(shaped, forms[0])
+		}
+	}
+	return shaped
+}
+
+// runeAt returns the character at i, or 0 past the end of the text.
+func runeAt(runes []rune, i int) rune {
+	if i < len(runes) {
+		return runes[i]
+	}
+	return 0
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// visualOrder reorders a line of text from logical order to the order
+// it is printed in, left to right. It follows the Unicode Bidirectional
+// Algorithm for text without explicit embeddings or isolates, with the
+// paragraph direction set by the first strong character, and mirrors
+// brackets in right-to-left runs.
+func visualOrder(runes []rune) []rune {
+	n := len(runes)
+	classes := make([]bidi.Class, n)
+	for i, r := range runes {
+		props, _ := bidi.LookupRune(r)
+		classes[i] = props.Class()
+	}
+	original := append([]bidi.Class(nil), classes...)
+
+	base := bidi.L
+	for _, class := range classes {
+		if class == bidi.L {
+			break
+		}
+		if class == bidi.R || class == bidi.AL {
+			base = bidi.R
+			break
+		}
+	}
+
+	// W1 to W3: marks take the type before them, numbers after Arabic
+	// letters are Arabic numbers and Arabic letters are right-to-left.
+	for i, class := range classes {
+		if class == bidi.NSM {
+			classes[i] = base
+			if i > 0 {
+				classes[i] = classes[i-1]
+			}
+		}
+	}
+	strong := base
+	for i, class := range classes {
+		switch class {
+		case bidi.L, bidi.R, bidi.AL:
+			strong = class
+		case bidi.EN:
+			if strong == bidi.AL {
+				class = bidi.AN
+			}
+		}
+		if class == bidi.AL {
+			class = bidi.R
+		}
+		classes[i] = class
+	}
+
+	// W4 and W5: separators between numbers and terminators next to
+	// European numbers join the numbers.
+	for i := 1; i < n-1; i++ {
+		before, after := classes[i-1], classes[i+1]
+		switch {
+		case classes[i] == bidi.ES && before == bidi.EN && after == bidi.EN:
+			classes[i] = bidi.EN
+		case classes[i] == bidi.CS && before == after && (before == bidi.EN || before == bidi.AN):
+			classes[i] = before
+		}
+	}
+	for i := 0; i < n; i++ {
+		if classes[i] != bidi.ET {
+			continue
+		}
+		end := i
+		for end < n && classes[end] == bidi.ET {
+			end++
+		}
+		if (i > 0 && classes[i-1] == bidi.EN) || (end < n && classes[end] == bidi.EN) {
+			for j := i; j < end; j++ {
+				classes[j] = bidi.EN
+			}
+		}
+		i = end - 1
+	}
+
+	// W6 and W7: remaining separators are neutral, and European numbers
+	// after left-to-right text are left-to-right.
+	strong = base
+	for i, class := range classes {
+		switch class {
+		case bidi.ES, bidi.ET, bidi.CS:
+			classes[i] = bidi.ON
+		case bidi.L, bidi.R:
+			strong = class
+		case bidi.EN:
+			if strong == bidi.L {
+				classes[i] = bidi.L
+			}
+		}
+	}
+
+	// N1 and N2: neutrals between characters of the same direction take
+	// it, and others take the paragraph direction.
+	direction := func(class bidi.Class) bidi.Class {
+		if class == bidi.L {
+			return bidi.L
+		}
+		return bidi.R
+	}
+	neutral := func(class bidi.Class) bool {
+		return class != bidi.L && class != bidi.R && class != bidi.EN && class != bidi.AN
+	}
+	for i := 0; i < n; i++ {
+		if !neutral(classes[i]) {
+			continue
+		}
+		end := i
+		for end < n && neutral(classes[end]) {
+			end++
+		}
+		before, after := base, base
+		if i > 0 {
+			before = direction(classes[i-1])
+		}
+		if end < n {
+			after = direction(classes[end])
+		}
+		resolved := base
+		if before == after {
+			resolved = before
+		}
+		for j := i; j < end; j++ {
+			classes[j] = resolved
+		}
+		i = end - 1
+	}
+
+	// I1 and I2 resolve the embedding levels, and L1 returns trailing
+	// whitespace to the paragraph level.
+	baseLevel := 0
+	if base == bidi.R {
+		baseLevel = 1
+	}
+	levels := make([]int, n)
+	for i, class := range classes {
+		switch {
+		case baseLevel == 0 && class == bidi.R:
+			levels[i] = 1
+		case baseLevel == 0 && (class == bidi.EN || class == bidi.AN):
+			levels[i] = 2
+		case baseLevel == 1 && class != bidi.R:
+			levels[i] = 2
+		default:
+			levels[i] = baseLevel
+		}
+	}
+	for i := n - 1; i >= 0 && (original[i] == bidi.WS || original[i] == bidi.S || original[i] == bidi.BN); i-- {
+		levels[i] = baseLevel
+	}
+
+	// L2 reverses every run at or above each level, from the highest
+	// level down to the lowest odd level, and L4 mirrors brackets.
+	visual := append([]rune(nil), runes...)
+	for i, level := range levels {
+		if mirrored, ok := mirroredBrackets[visual[i]]; ok && level%2 == 1 {
+			visual[i] = mirrored
+		}
+	}
+	highest := 0
+	for _, level := range levels {
+		highest = max(highest, level)
+	}
+	for level := highest; level >= 1; level-- {
+		for i := 0; i < n; i++ {
+			if levels[i] < level {
+				continue
+			}
+			end := i
+			for end < n && levels[end] >= level {
+				end++
+			}
+			for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+				visual[a], visual[b] = visual[b], visual[a]
+				levels[a], levels[b] = levels[b], levels[a]
+			}
+			i = end - 1
+		}
+	}
+
+	return visual
+}
+
+// renderText renders a line of text in the font as a grayscale image
+// as tall as the font's line, at the character size, emphasis,
+// underline, reverse and upside-down styles of the FormatConfig, with
+// double-strike drawn as emphasis. Right-to-left text is reordered and
+// Arabic shaped before rendering.
+func renderText(text string, rasterFont *opentype.Font, size float64, fmtCfg *FormatConfig) (*image.Gray, error) {
+	if fmtCfg.charWidth < 1 || fmtCfg.charHeight < 1 {
+		return nil, invalidConfig("invalid character size option in FormatConfig: %vx%v", fmtCfg.charWidth, fmtCfg.charHeight)
+	}
+
+	face, err := opentype.NewFace(rasterFont, &opentype.FaceOptions{
+		Size:    size * float64(fmtCfg.charHeight),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, invalidConfig("invalid raster font: %v", err)
+	}
+	defer face.Close()
+
+	visual := string(visualOrder(shapeArabic([]rune(text))))
+	metrics := face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	bold := fmtCfg.emphasis || fmtCfg.doubleStrike
+	width := font.MeasureString(face, visual).Ceil() + btoi(bold)
+	height := ascent + metrics.Descent.Ceil()
+
+	gray := image.NewGray(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.Draw(gray, gray.Bounds(), image.White, image.Point{}, draw.Src)
+
+	drawer := font.Drawer{Dst: gray, Src: image.Black, Face: face}
+	for x := 0; x <= btoi(bold); x++ {
+		drawer.Dot = fixed.P(x, ascent)
+		drawer.DrawString(visual)
+	}
+
+	thickness := map[string]int{"1-dot": 1, "2-dots": 2}[fmtCfg.underline]
+	underline := image.Rect(0, ascent+1, width, ascent+1+thickness)
+	draw.Draw(gray, underline, image.Black, image.Point{}, draw.Src)
+
+	if fmtCfg.charWidth != fmtCfg.charHeight {
+		gray = resizeGray(gray, max(width*int(fmtCfg.charWidth)/int(fmtCfg.charHeight), 1), gray.Bounds().Dy())
+	}
+
+	if fmtCfg.reverse {
+		for i := range gray.Pix {
+			gray.Pix[i] = 0xFF - gray.Pix[i]
+		}
+	}
+	if fmtCfg.upsideDown {
+		bounds := gray.Bounds()
+		rotated := image.NewGray(bounds)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				rotated.SetGray(bounds.Max.X-1-x+bounds.Min.X, bounds.Max.Y-1-y+bounds.Min.Y, gray.GrayAt(x, y))
+			}
+		}
+		gray = rotated
+	}
+	return gray, nil
+}
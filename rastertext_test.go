@@ -0,0 +1,144 @@
+package escpos
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+func TestShapeArabic(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"letters take their position in the word", "كباب", "ﻛﺒﺎﺏ"},
+		{"lam and alef form a ligature", "سلام", "ﺳﻼﻡ"},
+		{"lam and alef alone form an isolated ligature", "لا", "ﻻ"},
+		{"hamza never joins", "ماء", "ﻣﺎﺀ"},
+		{"marks do not break joining", "بَب", "ﺑَﺐ"},
+		{"words are shaped separately", "بب بب", "ﺑﺐ ﺑﺐ"},
+		{"other text is unchanged", "abc 12", "abc 12"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := string(shapeArabic([]rune(testCase.text)))
+
+			if got != testCase.want {
+				t.Errorf("shapeArabic did not return expected text: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"left-to-right text is unchanged", "Total: 5.00", "Total: 5.00"},
+		{"right-to-left text is reversed", "שלום", "םולש"},
+		{"right-to-left words in left-to-right text are reversed", "abc שלום def", "abc םולש def"},
+		{"numbers after right-to-left words join them", "abc שלום 123", "abc 123 םולש"},
+		{"left-to-right words in right-to-left text keep their order", "שלום abc def", "abc def םולש"},
+		{"numbers in right-to-left text keep their order", "שלום 12.50", "12.50 םולש"},
+		{"brackets in right-to-left text are mirrored", "(שלום)", "(םולש)"},
+		{"numbers after Arabic keep their order", "كباب 12", "12 بابك"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := string(visualOrder([]rune(testCase.text)))
+
+			if got != testCase.want {
+				t.Errorf("visualOrder did not return expected text: wanted %q, got %q", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	goFont, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatalf("could not parse font: %v", err)
+	}
+
+	fmtCfg := DefaultFormatConfig()
+	plain, err := renderText("Hello", goFont, 24, &fmtCfg)
+	if err != nil {
+		t.Fatalf("renderText returned error: %v", err)
+	}
+
+	t.Run("text is rendered at the font's line height", func(t *testing.T) {
+		if height := plain.Bounds().Dy(); height < 24 || height > 30 {
+			t.Errorf("rendered text was %v dots tall, wanted the line height of a 24 dot font", height)
+		}
+
+		black := 0
+		for _, value := range plain.Pix {
+			if value < 128 {
+				black++
+			}
+		}
+		if black == 0 {
+			t.Errorf("rendered text has no black dots")
+		}
+	})
+
+	t.Run("character size scales the text", func(t *testing.T) {
+		fmtCfg := DefaultFormatConfig().CharSize(2, 1)
+		wide, err := renderText("Hello", goFont, 24, &fmtCfg)
+
+		if err != nil {
+			t.Fatalf("renderText returned error: %v", err)
+		}
+
+		if wide.Bounds().Dx() != 2*plain.Bounds().Dx() || wide.Bounds().Dy() != plain.Bounds().Dy() {
+			t.Errorf("double width text was %v, wanted twice the width of %v", wide.Bounds(), plain.Bounds())
+		}
+	})
+
+	t.Run("reverse inverts the text", func(t *testing.T) {
+		fmtCfg := DefaultFormatConfig().Reverse(true)
+		reversed, err := renderText("Hello", goFont, 24, &fmtCfg)
+
+		if err != nil {
+			t.Fatalf("renderText returned error: %v", err)
+		}
+
+		for i := range plain.Pix {
+			if reversed.Pix[i] != 0xFF-plain.Pix[i] {
+				t.Fatalf("reversed text is not the inverse of plain text at %v", i)
+			}
+		}
+	})
+
+	t.Run("upside-down rotates the text by 180 degrees", func(t *testing.T) {
+		fmtCfg := DefaultFormatConfig().UpsideDown(true)
+		rotated, err := renderText("Hello", goFont, 24, &fmtCfg)
+
+		if err != nil {
+			t.Fatalf("renderText returned error: %v", err)
+		}
+
+		bounds := plain.Bounds()
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				if rotated.GrayAt(bounds.Dx()-1-x, bounds.Dy()-1-y) != plain.GrayAt(x, y) {
+					t.Fatalf("upside-down text does not match plain text rotated at %v,%v", x, y)
+				}
+			}
+		}
+	})
+
+	t.Run("invalid character size returns error", func(t *testing.T) {
+		fmtCfg := DefaultFormatConfig().CharSize(0, 1)
+
+		if _, err := renderText("Hello", goFont, 24, &fmtCfg); err == nil {
+			t.Errorf("renderText did not return error")
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package escpos
+
+import "strings"
+
+// PrinterInfo identifies a printer, as reported by the printer itself.
+type PrinterInfo struct {
+	// ModelID is the manufacturer's code for the printer model.
+	ModelID byte
+	// TypeID describes the printer's hardware. On Epson printers, bit 0
+	// is set when multi-byte characters are supported, bit 1 when an
+	// autocutter is fitted and bit 2 when a customer display is fitted.
+	TypeID byte
+
+	FirmwareVersion string
+	Manufacturer    string
+	ModelName       string
+	SerialNumber    string
+}
+
+// ProfileRegistry maps the model names printers report to the profiles
+// for them, so the profile can be chosen once the printer has been
+// identified.
+type ProfileRegistry struct {
+	profiles map[string]Profile
+	fallback Profile
+}
+
+// NewProfileRegistry creates an empty registry which returns the
+// fallback profile for printers with no registered profile. The
+// fallback is also used to identify printers, so it should implement
+// Identification.
+func NewProfileRegistry(fallback Profile) *ProfileRegistry {
+	return &ProfileRegistry{
+		profiles: map[string]Profile{},
+		fallback: fallback,
+	}
+}
+
+// DefaultProfileRegistry creates a registry holding the profiles
+// provided by this package, keyed by the model names the printers
+// report, and falling back to Generic58mm.
+func DefaultProfileRegistry() *ProfileRegistry {
+	registry := NewProfileRegistry(Generic58mm{})
+	registry.Register("TM-T20III", EpsonTMT20III{})
+	registry.Register("TM-T88VII", EpsonTMT88VII{})
+	return registry
+}
+
+// Register sets the profile for printers reporting the model name,
+// replacing any profile already registered for it.
+func (registry *ProfileRegistry) Register(modelName string, profile Profile) {
+	registry.profiles[modelName] = profile
+}
+
+// Lookup returns the profile registered for the model name of the
+// printer, or the fallback profile if there is none.
+func (registry *ProfileRegistry) Lookup(info PrinterInfo) Profile {
+	if profile, ok := registry.profiles[strings.TrimSpace(info.ModelName)]; ok {
+		return profile
+	}
+	return registry.fallback
+}
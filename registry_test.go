@@ -0,0 +1,36 @@
+package escpos
+
+import "testing"
+
+func TestProfileRegistry(t *testing.T) {
+	registry := DefaultProfileRegistry()
+
+	cases := []struct {
+		name      string
+		modelName string
+		want      Profile
+	}{
+		{"TM-T20III uses its profile", "TM-T20III", EpsonTMT20III{}},
+		{"TM-T88VII uses its profile", "TM-T88VII", EpsonTMT88VII{}},
+		{"padded model name uses its profile", "TM-T88VII  ", EpsonTMT88VII{}},
+		{"unknown model uses fallback", "POS-58", Generic58mm{}},
+		{"empty model name uses fallback", "", Generic58mm{}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := registry.Lookup(PrinterInfo{ModelName: testCase.modelName}); got != testCase.want {
+				t.Errorf("Lookup returned %T, wanted %T", got, testCase.want)
+			}
+		})
+	}
+
+	t.Run("registering replaces profile", func(t *testing.T) {
+		registry := DefaultProfileRegistry()
+		registry.Register("TM-T20III", EpsonTMT20III{Encoding: "Shift-JIS"})
+
+		if got := registry.Lookup(PrinterInfo{ModelName: "TM-T20III"}); got != (EpsonTMT20III{Encoding: "Shift-JIS"}) {
+			t.Errorf("Lookup did not return registered profile, got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,368 @@
+package escpos
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Status is the real-time status of the printer. Each status query sets
+// the fields it reports on and leaves the others false.
+type Status struct {
+	// DrawerPinHigh reports whether pin 3 of the drawer kick-out
+	// connector is high, which for most drawers means it is closed.
+	DrawerPinHigh bool
+	// Offline reports whether the printer is offline.
+	Offline bool
+	// FeedButtonPressed reports whether the paper feed button is
+	// pressed.
+	FeedButtonPressed bool
+
+	// CoverOpen reports whether the printer cover is open.
+	CoverOpen bool
+	// PaperFeeding reports whether paper is being fed by the feed
+	// button.
+	PaperFeeding bool
+	// Error reports whether an error has occurred.
+	Error bool
+
+	// CutterError reports whether the autocutter has failed.
+	CutterError bool
+	// UnrecoverableError reports whether an error has occurred which
+	// needs the printer to be switched off and on again.
+	UnrecoverableError bool
+	// AutoRecoverableError reports whether an error has occurred which
+	// the printer recovers from once the cause is fixed, such as the
+	// print head overheating.
+	AutoRecoverableError bool
+
+	// PaperNearEnd reports whether the roll paper is nearly used up.
+	PaperNearEnd bool
+	// PaperEnd reports whether the roll paper has run out.
+	PaperEnd bool
+}
+
+// EventKind is the kind of change in the printer's status an Event
+// reports.
+type EventKind string
+
+const (
+	EventPaperLow       EventKind = "paper low"
+	EventPaperOut       EventKind = "paper out"
+	EventPaperLoaded    EventKind = "paper loaded"
+	EventCoverOpened    EventKind = "cover opened"
+	EventCoverClosed    EventKind = "cover closed"
+	EventError          EventKind = "error"
+	EventErrorRecovered EventKind = "error recovered"
+	EventOffline        EventKind = "offline"
+	EventOnline         EventKind = "online"
+)
+
+// Event is a change in the printer's status, reported by automatic
+// status back. Status is the printer's status after the change.
+type Event struct {
+	Kind   EventKind
+	Status Status
+}
+
+// statusEvents returns the events for the changes between two statuses.
+func statusEvents(previous Status, status Status) []Event {
+	var events []Event
+	changed := func(before bool, after bool, set EventKind, cleared EventKind) {
+		switch {
+		case !before && after && set != "":
+			events = append(events, Event{Kind: set, Status: status})
+		case before && !after && cleared != "":
+			events = append(events, Event{Kind: cleared, Status: status})
+		}
+	}
+
+	changed(previous.Offline, status.Offline, EventOffline, EventOnline)
+	changed(previous.CoverOpen, status.CoverOpen, EventCoverOpened, EventCoverClosed)
+	changed(previous.PaperNearEnd, status.PaperNearEnd, EventPaperLow, "")
+	changed(previous.PaperEnd, status.PaperEnd, EventPaperOut, EventPaperLoaded)
+	changed(previous.Error, status.Error, EventError, EventErrorRecovered)
+	return events
+}
+
+// replyReader reads from the printer in the background, separating the
+// automatic status back messages it sends unprompted, which are
+// published as events, and process ID responses from replies to
+// queries.
+type replyReader struct {
+	reader     io.Reader
+	replies    chan byte
+	blocks     chan []byte
+	processIDs chan string
+	done       chan struct{}
+	stopped    chan struct{}
+	err        error
+
+	mu        sync.Mutex
+	profile   Profile
+	asb       AutomaticStatusBack
+	events    chan Event
+	processID int
+}
+
+func newReplyReader(reader io.Reader, profile Profile) *replyReader {
+	replies := &replyReader{
+		reader:     reader,
+		replies:    make(chan byte, 256),
+		blocks:     make(chan []byte, 16),
+		processIDs: make(chan string, 16),
+		profile:    profile,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+		processID:  rand.IntN(10000),
+	}
+	go replies.run()
+	return replies
+}
+
+// run reads until the reader fails or the reader is stopped, then
+// records the error and closes the events channel.
+func (replies *replyReader) run() {
+	defer func() {
+		replies.mu.Lock()
+		if replies.events != nil {
+			close(replies.events)
+			replies.events = nil
+		}
+		replies.mu.Unlock()
+		close(replies.done)
+	}()
+
+	var status Status
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(replies.reader, b[:]); err != nil {
+			replies.err = err
+			return
+		}
+		select {
+		case <-replies.stopped:
+			return
+		default:
+		}
+
+		replies.mu.Lock()
+		profile, asb := replies.profile, replies.asb
+		replies.mu.Unlock()
+
+		if info, ok := profile.(Identification); ok && info.IdentificationBlock(b[0]) {
+			block, err := replies.block(b[0])
+			if err != nil {
+				return
+			}
+			select {
+			case replies.blocks <- block:
+			default:
+			}
+			continue
+		}
+
+		if pid, ok := profile.(ProcessID); ok {
+			if length := pid.ProcessIDResponseLength(b[0]); length > 0 {
+				response, err := replies.message(b[0], length)
+				if err != nil {
+					return
+				}
+				if id, err := pid.DecodeProcessIDResponse(response); err == nil {
+					// As with replies, responses nobody waits for are
+					// dropped once the buffer is full.
+					select {
+					case replies.processIDs <- id:
+					default:
+					}
+				}
+				continue
+			}
+		}
+
+		length := 0
+		if asb != nil {
+			length = asb.AutomaticStatusBackLength(b[0])
+		}
+		if length == 0 {
+			// Replies nobody waits for are dropped once the buffer is
+			// full, as the next query discards them anyway.
+			select {
+			case replies.replies <- b[0]:
+			default:
+			}
+			continue
+		}
+
+		message, err := replies.message(b[0], length)
+		if err != nil {
+			return
+		}
+
+		var next Status
+		if asb.DecodeAutomaticStatusBack(message, &next) != nil {
+			continue
+		}
+		replies.publish(statusEvents(status, next))
+		status = next
+	}
+}
+
+// publish sends the events on the events channel, dropping them if the
+// channel is full. The lock is held while sending, so the channel is not
+// closed by stop in the meantime.
+func (replies *replyReader) publish(events []Event) {
+	replies.mu.Lock()
+	defer replies.mu.Unlock()
+	if replies.events == nil {
+		return
+	}
+	for _, event := range events {
+		select {
+		case replies.events <- event:
+		default:
+		}
+	}
+}
+
+// stop makes the reader stop once its pending read returns, closes the
+// events channel and makes waiting for replies return ErrClosed.
+func (replies *replyReader) stop() {
+	replies.mu.Lock()
+	defer replies.mu.Unlock()
+	select {
+	case <-replies.stopped:
+		return
+	default:
+	}
+
+	close(replies.stopped)
+	if replies.events != nil {
+		close(replies.events)
+		replies.events = nil
+	}
+	replies.asb = nil
+}
+
+// message reads the rest of a message of the given length starting with
+// the first byte, recording the error if reading fails.
+func (replies *replyReader) message(first byte, length int) ([]byte, error) {
+	message := make([]byte, length)
+	message[0] = first
+	if _, err := io.ReadFull(replies.reader, message[1:]); err != nil {
+		replies.err = err
+		return nil, err
+	}
+	return message, nil
+}
+
+// block reads the rest of a reply starting with the first byte and
+// ending with a NUL byte, recording the error if reading fails.
+func (replies *replyReader) block(first byte) ([]byte, error) {
+	block := []byte{first}
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(replies.reader, b[:]); err != nil {
+			replies.err = err
+			return nil, err
+		}
+		block = append(block, b[0])
+		if b[0] == 0x00 {
+			return block, nil
+		}
+	}
+}
+
+// newProcessID returns the next four-digit process ID. IDs start at a
+// random number, so responses meant for another connection to the
+// printer are unlikely to match.
+func (replies *replyReader) newProcessID() string {
+	replies.mu.Lock()
+	defer replies.mu.Unlock()
+	id := fmt.Sprintf("%04d", replies.processID)
+	replies.processID = (replies.processID + 1) % 10000
+	return id
+}
+
+// setProfile changes the profile messages from the printer are told
+// apart with.
+func (replies *replyReader) setProfile(profile Profile) {
+	replies.mu.Lock()
+	replies.profile = profile
+	replies.mu.Unlock()
+}
+
+// discard drops replies which arrived after the query they answered
+// timed out, so they are not mistaken for the reply to the next query.
+func (replies *replyReader) discard() {
+	for {
+		select {
+		case <-replies.replies:
+		case <-replies.blocks:
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next byte of a reply, waiting at most the timeout.
+func (replies *replyReader) next(timeout time.Duration) (byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case b := <-replies.replies:
+		return b, nil
+	case <-replies.stopped:
+		return 0, ErrClosed
+	case <-replies.done:
+		return 0, &ReadError{Err: replies.err}
+	case <-timer.C:
+		return 0, ErrTimeout
+	}
+}
+
+// nextReply returns the next reply, either a single byte or a block
+// ending with NUL, waiting at most the timeout.
+func (replies *replyReader) nextReply(timeout time.Duration) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case b := <-replies.replies:
+		return []byte{b}, nil
+	case block := <-replies.blocks:
+		return block, nil
+	case <-replies.stopped:
+		return nil, ErrClosed
+	case <-replies.done:
+		return nil, &ReadError{Err: replies.err}
+	case <-timer.C:
+		return nil, ErrTimeout
+	}
+}
+
+// awaitProcessID waits at most the timeout for the printer to respond
+// with the process ID, skipping responses to earlier process IDs which
+// timed out.
+func (replies *replyReader) awaitProcessID(id string, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case response := <-replies.processIDs:
+			if response == id {
+				return nil
+			}
+		case <-replies.stopped:
+			return ErrClosed
+		case <-replies.done:
+			return &ReadError{Err: replies.err}
+		case <-timer.C:
+			return ErrTimeout
+		}
+	}
+}
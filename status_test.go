@@ -0,0 +1,40 @@
+package escpos
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStatusEvents(t *testing.T) {
+	cases := []struct {
+		name     string
+		previous Status
+		status   Status
+		want     []EventKind
+	}{
+		{"no change", Status{PaperNearEnd: true}, Status{PaperNearEnd: true}, nil},
+		{"paper runs low", Status{}, Status{PaperNearEnd: true}, []EventKind{EventPaperLow}},
+		{"paper runs out", Status{PaperNearEnd: true}, Status{PaperNearEnd: true, PaperEnd: true}, []EventKind{EventPaperOut}},
+		{"paper is loaded", Status{PaperNearEnd: true, PaperEnd: true}, Status{}, []EventKind{EventPaperLoaded}},
+		{"cover is opened", Status{}, Status{CoverOpen: true, Offline: true}, []EventKind{EventOffline, EventCoverOpened}},
+		{"cover is closed", Status{CoverOpen: true, Offline: true}, Status{}, []EventKind{EventOnline, EventCoverClosed}},
+		{"error occurs", Status{}, Status{Error: true, CutterError: true}, []EventKind{EventError}},
+		{"error is recovered", Status{Error: true, CutterError: true}, Status{}, []EventKind{EventErrorRecovered}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got []EventKind
+			for _, event := range statusEvents(testCase.previous, testCase.status) {
+				if event.Status != testCase.status {
+					t.Errorf("event did not carry the new status, got %+v", event.Status)
+				}
+				got = append(got, event.Kind)
+			}
+
+			if !slices.Equal(got, testCase.want) {
+				t.Errorf("statusEvents did not return expected events: wanted %v, got %v", testCase.want, got)
+			}
+		})
+	}
+}